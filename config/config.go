@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -18,6 +20,18 @@ const (
 	StatusFailed    = "failed"
 )
 
+// Update policies, from most to least conservative
+const (
+	PolicyManual    = "manual"     // never installed automatically, not announced
+	PolicyNotify    = "notify"     // announced by check --notify (the default)
+	PolicyAutoPatch = "auto-patch" // patch releases are installed by auto-update
+	PolicyAutoMinor = "auto-minor" // minor and patch releases
+	PolicyAuto      = "auto"       // every newer release
+)
+
+// UpdatePolicies lists the valid update policies.
+var UpdatePolicies = []string{PolicyManual, PolicyNotify, PolicyAutoPatch, PolicyAutoMinor, PolicyAuto}
+
 type App struct {
 	Name        string `json:"name"`
 	RepoURL     string `json:"repo_url"`
@@ -27,53 +41,258 @@ type App struct {
 
 	InstallMethod string `json:"install_method,omitempty"`
 	BinaryPath    string `json:"binary_path,omitempty"`
+	BinaryDigest  string `json:"binary_digest,omitempty"` // SHA-256 of the installed binary
+	AssetDigest   string `json:"asset_digest,omitempty"`  // SHA-256 of the release asset installed
 	InstallStatus string `json:"install_status,omitempty"`
 	InstallError  string `json:"install_error,omitempty"`
+
+	// AssetPattern selects the release asset on install and update.
+	// See installer.CompilePattern for the syntax.
+	AssetPattern string `json:"asset_pattern,omitempty"`
+
+	// Pin holds the app at a release tag instead of following its channel.
+	Pin string `json:"pin,omitempty"`
+
+	// Channel overrides the channel setting for this app.
+	Channel string `json:"channel,omitempty"`
+
+	// UpdatePolicy decides what auto-update may install; empty means
+	// PolicyNotify.
+	UpdatePolicy string `json:"update_policy,omitempty"`
+
+	// VersionArgs are the arguments that make the binary print its
+	// version; empty tries --version, -V, -v and version.
+	VersionArgs []string `json:"version_args,omitempty"`
+
+	// VersionPattern is a regular expression extracting the version from
+	// that output, from its first capture group when it has one.
+	VersionPattern string `json:"version_pattern,omitempty"`
+
+	// Adopted marks an app that was installed outside autonomix and
+	// tracked with adopt. Removing it leaves the installed files alone
+	// until autonomix installs a release of it itself.
+	Adopted bool `json:"adopted,omitempty"`
 }
 
-type Config struct {
-	Apps []App `json:"apps"`
+// Policy returns the app's update policy with the default applied.
+func (a App) Policy() string {
+	if a.UpdatePolicy == "" {
+		return PolicyNotify
+	}
+	return a.UpdatePolicy
 }
 
+type Config struct {
+	SchemaVersion int   `json:"schema_version"`
+	Apps          []App `json:"apps"`
 
+	Settings Settings `json:"settings"`
 
-func GetConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
+	invalid  []json.RawMessage
+	problems []error
+}
+
+
+func GetConfigPath() (string, error) {
+	dir, err := GetConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".autonomix"), nil
+	return filepath.Join(dir, "config.json"), nil
 }
 
-func GetConfigPath() (string, error) {
+func GetLockPath() (string, error) {
 	dir, err := GetConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "config.json"), nil
+	return filepath.Join(dir, "config.lock"), nil
+}
+
+// withLock runs fn while holding the advisory lock on config.lock, shared
+// for readers and exclusive for writers.
+func withLock(exclusive bool, fn func() error) error {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	lockPath, err := GetLockPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, exclusive); err != nil {
+		return fmt.Errorf("failed to lock config: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
 }
 
+var readOnly bool
+
+// SetReadOnly stops the config from being written, for dry runs. Load then
+// migrates older configs in memory only, without a backup or a lock file,
+// and Update fails.
+func SetReadOnly(v bool) {
+	readOnly = v
+}
+
+// Load reads the config. The returned copy is a snapshot; use Update to
+// change the config so concurrent writers do not clobber each other.
+// Configs from older schema versions are migrated and saved once.
 func Load() (*Config, error) {
+	if readOnly {
+		// Saves replace the file atomically, so reading unlocked is safe
+		cfg, _, _, err := read()
+		return cfg, err
+	}
+
+	var cfg *Config
+	var from int
+	err := withLock(false, func() error {
+		var err error
+		cfg, from, _, err = read()
+		return err
+	})
+	if err == nil && from < SchemaVersion {
+		// The backup and the migrated save both happen under the
+		// exclusive lock, so concurrent loads cannot race on them
+		return Update(func(*Config) error { return nil })
+	}
+	return cfg, err
+}
+
+// Update applies fn to the current config and saves the result, holding
+// an exclusive lock for the whole read-modify-write cycle. If fn returns an
+// error nothing is written. The saved config is returned.
+func Update(fn func(*Config) error) (*Config, error) {
+	if readOnly {
+		return nil, errors.New("config is read-only")
+	}
+
+	var cfg *Config
+	err := withLock(true, func() error {
+		var err error
+		cfg, err = load()
+		if err != nil {
+			return err
+		}
+		if err := fn(cfg); err != nil {
+			return err
+		}
+		return save(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// load reads and migrates the config file, backing up a file written by an
+// older schema version before it is saved. Callers hold the exclusive lock.
+func load() (*Config, error) {
+	cfg, from, data, err := read()
+	if err != nil || from >= SchemaVersion {
+		return cfg, err
+	}
+
 	path, err := GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
+	if err := backupConfig(path, from, data); err != nil {
+		return nil, fmt.Errorf("failed to back up config before migration: %w", err)
+	}
+	return cfg, nil
+}
+
+// read parses the config file, migrating it in memory. It returns the
+// schema version the file was written with and its contents.
+func read() (*Config, int, []byte, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, 0, nil, err
+	}
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return &Config{Apps: []App{}}, nil
+		return &Config{SchemaVersion: SchemaVersion, Apps: []App{}}, SchemaVersion, nil, nil
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	cfg, from, err := parse(data)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("invalid config %s: %w", path, err)
 	}
+	return cfg, from, data, nil
+}
 
-	return &cfg, nil
+// Parse decodes config file contents, migrating older schemas in memory.
+func Parse(data []byte) (*Config, error) {
+	cfg, _, err := parse(data)
+	return cfg, err
+}
+
+func parse(data []byte) (*Config, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	from, err := migrate(doc)
+	if err != nil {
+		return nil, from, err
+	}
+
+	cfg, err := decode(doc)
+	if err != nil {
+		return nil, from, err
+	}
+	return cfg, from, nil
+}
+
+// FindApp returns the tracked app with the given repository URL.
+func (c *Config) FindApp(repoURL string) *App {
+	for i := range c.Apps {
+		if strings.EqualFold(c.Apps[i].RepoURL, repoURL) {
+			return &c.Apps[i]
+		}
+	}
+	return nil
+}
+
+// FindAppByName returns the tracked app with the given name.
+func (c *Config) FindAppByName(name string) *App {
+	for i := range c.Apps {
+		if c.Apps[i].Name == name {
+			return &c.Apps[i]
+		}
+	}
+	return nil
+}
+
+// RemoveApp stops tracking the app with the given repository URL.
+func (c *Config) RemoveApp(repoURL string) bool {
+	for i := range c.Apps {
+		if strings.EqualFold(c.Apps[i].RepoURL, repoURL) {
+			c.Apps = append(c.Apps[:i], c.Apps[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
 
 func detectInstallMethod(app *App) string {
@@ -94,7 +313,13 @@ func detectInstallMethod(app *App) string {
 	return InstallMethodBinary
 }
 
-func Save(cfg *Config) error {
+// fileMode is the mode of config.json and its backups. They can hold the
+// GitHub token, so only the owner may read them.
+const fileMode os.FileMode = 0600
+
+// save writes cfg to a temporary file and renames it over config.json so
+// readers never see a partially written file. Callers must hold the lock.
+func save(cfg *Config) error {
 	dir, err := GetConfigDir()
 	if err != nil {
 		return err
@@ -104,22 +329,38 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	// Migrate on save if needed
-	for i := range cfg.Apps {
-		if cfg.Apps[i].InstallMethod == "" && cfg.Apps[i].Version != "" {
-			cfg.Apps[i].InstallMethod = detectInstallMethod(&cfg.Apps[i])
-		}
+	path, err := GetConfigPath()
+	if err != nil {
+		return err
 	}
 
-	path, err := GetConfigPath()
+	cfg.SchemaVersion = SchemaVersion
+	data, err := encode(cfg)
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	tmp, err := os.CreateTemp(dir, "config.json.tmp-*")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
 
-	return os.WriteFile(path, data, 0644)
+	return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateConcurrentWritersKeepAllApps(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := Update(func(c *Config) error {
+				c.Apps = append(c.Apps, App{
+					Name:    fmt.Sprintf("app%d", i),
+					RepoURL: fmt.Sprintf("https://github.com/o/app%d", i),
+				})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Apps) != writers {
+		t.Fatalf("got %d apps, want %d", len(cfg.Apps), writers)
+	}
+}
+
+func TestUpdateErrorWritesNothing(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	if _, err := Update(func(c *Config) error {
+		c.Apps = append(c.Apps, App{Name: "a", RepoURL: "https://github.com/o/a"})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("boom")
+	_, err := Update(func(c *Config) error {
+		c.RemoveApp("https://github.com/o/a")
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("got %v, want %v", err, boom)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FindApp("https://github.com/O/A") == nil {
+		t.Fatal("app removed despite failed update")
+	}
+}
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMigratesUnversionedConfig(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	t.Setenv("PATH", "")
+
+	original := `{"apps":[{"name":"tool","repo_url":"https://github.com/o/tool","version":"v1.0.0","latest":"v1.0.0","last_checked":""}],"cache_max_size":1024}`
+	path := writeConfig(t, original)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SchemaVersion != SchemaVersion {
+		t.Fatalf("schema version %d, want %d", cfg.SchemaVersion, SchemaVersion)
+	}
+	if len(cfg.Apps) != 1 || cfg.Apps[0].Name != "tool" {
+		t.Fatalf("unexpected apps %+v", cfg.Apps)
+	}
+
+	if cfg.Settings.CacheMaxSize != 1024 {
+		t.Errorf("cache_max_size not moved into settings: %+v", cfg.Settings)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("no backup written: %v", err)
+	}
+	if string(backup) != original {
+		t.Fatalf("backup %q does not match original", backup)
+	}
+	for _, p := range []string{path, path + ".v0.bak"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != fileMode {
+			t.Errorf("%s has mode %v, want %v", p, info.Mode().Perm(), fileMode)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), fmt.Sprintf(`"schema_version": %d`, SchemaVersion)) {
+		t.Fatalf("migrated config not saved:\n%s", data)
+	}
+}
+
+func TestConcurrentLoadsMigrateOnce(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	t.Setenv("PATH", "")
+
+	original := `{"apps":[{"name":"tool","repo_url":"https://github.com/o/tool","version":"v1.0.0","latest":"v1.0.0","last_checked":""}]}`
+	path := writeConfig(t, original)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Load(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("no backup written: %v", err)
+	}
+	if string(backup) != original {
+		t.Fatalf("backup %q does not match original", backup)
+	}
+}
+
+func TestLoadReportsMalformedApps(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	path := writeConfig(t, `{"schema_version":1,"apps":[
+		{"name":"good","repo_url":"https://github.com/o/good"},
+		{"name":"bad","repo_url":42},
+		{"name":"nourl"}
+	]}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Apps) != 1 || cfg.Apps[0].Name != "good" {
+		t.Fatalf("unexpected apps %+v", cfg.Apps)
+	}
+	if len(cfg.Problems()) != 2 {
+		t.Fatalf("got problems %v, want 2", cfg.Problems())
+	}
+
+	// Saving keeps the malformed entries for the user to fix
+	if _, err := Update(func(c *Config) error {
+		c.Apps = append(c.Apps, App{Name: "new", RepoURL: "https://github.com/o/new"})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"nourl"`, `"bad"`, `"new"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("saved config lost %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestLoadRejectsNewerSchema(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	writeConfig(t, `{"schema_version":99,"apps":[]}`)
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for newer schema version")
+	}
+}
+
+func TestReadOnlyLoadWritesNothing(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	SetReadOnly(true)
+	t.Cleanup(func() { SetReadOnly(false) })
+
+	original := `{"apps":[{"name":"tool","repo_url":"https://github.com/o/tool"}],"cache_max_size":1024}`
+	path := writeConfig(t, original)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Settings.CacheMaxSize != 1024 || len(cfg.Apps) != 1 {
+		t.Errorf("config not migrated in memory: %+v", cfg)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != original {
+		t.Errorf("read-only load rewrote the config:\n%s", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("read-only load left %d files, want only config.json", len(entries))
+	}
+
+	if _, err := Update(func(*Config) error { return nil }); err == nil {
+		t.Error("Update succeeded on a read-only config")
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SchemaVersion is the config schema written by this build. Files without a
+// schema_version are version 0.
+const SchemaVersion = 2
+
+// migration upgrades a raw config document from version-1 to version.
+type migration struct {
+	version     int
+	description string
+	migrate     func(doc map[string]any) error
+}
+
+// migrations are applied in order to configs older than SchemaVersion.
+// Append new steps here and bump SchemaVersion; never edit released ones.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "detect install method of installed apps",
+		migrate:     migrateInstallMethods,
+	},
+	{
+		version:     2,
+		description: "move cache_max_size into settings",
+		migrate:     migrateSettings,
+	},
+}
+
+// migrateInstallMethods fills in install_method for apps that were
+// installed before the field existed. It used to run on every save.
+func migrateInstallMethods(doc map[string]any) error {
+	apps, _ := doc["apps"].([]any)
+	for _, entry := range apps {
+		app, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		method, _ := app["install_method"].(string)
+		version, _ := app["version"].(string)
+		name, _ := app["name"].(string)
+		if method != "" || version == "" || name == "" {
+			continue
+		}
+
+		detected := App{Name: name}
+		if m := detectInstallMethod(&detected); m != InstallMethodUnknown {
+			app["install_method"] = m
+			app["binary_path"] = detected.BinaryPath
+		}
+	}
+	return nil
+}
+
+// migrateSettings moves top-level preferences into the settings section.
+func migrateSettings(doc map[string]any) error {
+	settings, _ := doc["settings"].(map[string]any)
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	if size, ok := doc["cache_max_size"]; ok {
+		settings["cache_max_size"] = size
+		delete(doc, "cache_max_size")
+	}
+	doc["settings"] = settings
+	return nil
+}
+
+// migrate upgrades doc in place to SchemaVersion and reports the version
+// it started from.
+func migrate(doc map[string]any) (int, error) {
+	from := 0
+	if v, ok := doc["schema_version"].(float64); ok {
+		from = int(v)
+	}
+	if from > SchemaVersion {
+		return from, fmt.Errorf("config schema version %d is newer than supported version %d; upgrade autonomix-cli", from, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return from, fmt.Errorf("config migration to version %d (%s) failed: %w", m.version, m.description, err)
+		}
+		doc["schema_version"] = m.version
+	}
+	return from, nil
+}
+
+// backupConfig keeps a copy of a config file before it is first rewritten
+// by a migration. An existing backup of the same version is left alone.
+func backupConfig(path string, version int, data []byte) error {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	return os.WriteFile(backup, data, fileMode)
+}
+
+// decode builds a Config from a migrated document. Malformed app entries
+// are skipped and reported through Problems rather than failing the whole
+// file; they are kept verbatim so saving does not lose them.
+func decode(doc map[string]any) (*Config, error) {
+	rawApps, _ := doc["apps"].([]any)
+	rest := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if k != "apps" {
+			rest[k] = v
+		}
+	}
+
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.Apps = []App{}
+	for i, entry := range rawApps {
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		var app App
+		if err := json.Unmarshal(raw, &app); err != nil {
+			cfg.reject(raw, fmt.Errorf("app #%d: %w", i+1, err))
+			continue
+		}
+		if err := validateApp(app); err != nil {
+			cfg.reject(raw, fmt.Errorf("app #%d: %w", i+1, err))
+			continue
+		}
+		cfg.Apps = append(cfg.Apps, app)
+	}
+	return &cfg, nil
+}
+
+func validateApp(app App) error {
+	if strings.TrimSpace(app.RepoURL) == "" {
+		return fmt.Errorf("missing repo_url")
+	}
+	if strings.TrimSpace(app.Name) == "" {
+		return fmt.Errorf("%s: missing name", app.RepoURL)
+	}
+	return nil
+}
+
+func (c *Config) reject(raw json.RawMessage, err error) {
+	c.invalid = append(c.invalid, raw)
+	c.problems = append(c.problems, err)
+}
+
+// Problems reports app entries that could not be loaded. They are left in
+// the file untouched until fixed by hand.
+func (c *Config) Problems() []error {
+	return c.problems
+}
+
+// encode marshals cfg, appending any malformed app entries it was loaded
+// with.
+func encode(cfg *Config) ([]byte, error) {
+	if len(cfg.invalid) == 0 {
+		return json.MarshalIndent(cfg, "", "  ")
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	apps, _ := doc["apps"].([]any)
+	for _, raw := range cfg.invalid {
+		apps = append(apps, raw)
+	}
+	doc["apps"] = apps
+	return json.MarshalIndent(doc, "", "  ")
+}
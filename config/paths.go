@@ -0,0 +1,289 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// HomeEnv overrides every autonomix directory with subdirectories of a
+// single root, mainly for tests and CI.
+const HomeEnv = "AUTONOMIX_HOME"
+
+// baseDir resolves an XDG base directory for autonomix. sub is used under
+// AUTONOMIX_HOME, xdgEnv names the XDG variable and fallback is its default
+// relative to the home directory.
+func baseDir(sub, xdgEnv, fallback string) (string, error) {
+	if root := os.Getenv(HomeEnv); root != "" {
+		return filepath.Join(root, sub), nil
+	}
+	// The spec requires absolute paths; relative values are ignored
+	if dir := os.Getenv(xdgEnv); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "autonomix"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, "autonomix"), nil
+}
+
+// GetConfigDir returns where config.json lives, $XDG_CONFIG_HOME/autonomix
+// by default.
+func GetConfigDir() (string, error) {
+	if legacy := legacyInPlace(); legacy != "" {
+		return legacy, nil
+	}
+	migrateLegacyOnce()
+	return baseDir("config", "XDG_CONFIG_HOME", ".config")
+}
+
+// GetStateDir returns the directory for logs and other state,
+// $XDG_STATE_HOME/autonomix by default.
+func GetStateDir() (string, error) {
+	migrateLegacyOnce()
+	return baseDir("state", "XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// GetCacheDir returns the directory for disposable data such as downloads,
+// $XDG_CACHE_HOME/autonomix by default.
+func GetCacheDir() (string, error) {
+	if legacy := legacyInPlace(); legacy != "" {
+		return filepath.Join(legacy, "cache"), nil
+	}
+	migrateLegacyOnce()
+	return baseDir("cache", "XDG_CACHE_HOME", ".cache")
+}
+
+// GetDataDir returns the directory for kept binary versions and the
+// autonomix bin directory, $XDG_DATA_HOME/autonomix by default.
+func GetDataDir() (string, error) {
+	if legacy := legacyInPlace(); legacy != "" {
+		return legacy, nil
+	}
+	migrateLegacyOnce()
+	return baseDir("data", "XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// LegacyDir is the single directory used by releases before XDG support.
+func LegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".autonomix"), nil
+}
+
+var (
+	legacyOnce sync.Once
+	legacyErr  error
+)
+
+func migrateLegacyOnce() {
+	if readOnly {
+		return
+	}
+	legacyOnce.Do(func() {
+		legacyErr = migrateLegacy()
+		if legacyErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: migrating ~/.autonomix: %v\n", legacyErr)
+		}
+	})
+}
+
+// unmigratedLegacy returns ~/.autonomix when it still has to be moved
+// into the XDG directories: it exists, AUTONOMIX_HOME is unset and no
+// config exists in the new location.
+func unmigratedLegacy() string {
+	if os.Getenv(HomeEnv) != "" {
+		return ""
+	}
+	legacy, err := LegacyDir()
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Lstat(legacy); err != nil || !info.IsDir() {
+		return ""
+	}
+	configDir, err := baseDir("config", "XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "config.json")); err == nil {
+		return ""
+	}
+	return legacy
+}
+
+// legacyInPlace returns the unmigrated ~/.autonomix in read-only mode,
+// which uses its config, cache and versions where they are instead of
+// moving them.
+func legacyInPlace() string {
+	if !readOnly {
+		return ""
+	}
+	return unmigratedLegacy()
+}
+
+// migrateLegacy moves ~/.autonomix into the XDG directories the first time
+// a release with XDG support runs. Nothing happens under AUTONOMIX_HOME or
+// once a config exists in the new location.
+func migrateLegacy() error {
+	legacy := unmigratedLegacy()
+	if legacy == "" {
+		return nil
+	}
+
+	configDir, err := baseDir("config", "XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return err
+	}
+	cacheDir, err := baseDir("cache", "XDG_CACHE_HOME", ".cache")
+	if err != nil {
+		return err
+	}
+	dataDir, err := baseDir("data", "XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return err
+	}
+
+	oldVersions := filepath.Join(legacy, "versions")
+	newVersions := filepath.Join(dataDir, "versions")
+	oldBin := filepath.Join(legacy, "bin")
+	newBin := filepath.Join(dataDir, "bin")
+
+	var errs []error
+	if err := migrateLegacyConfig(legacy, configDir, oldBin, newBin); err != nil {
+		errs = append(errs, err)
+	}
+	moves := [][2]string{
+		{filepath.Join(legacy, "cache", "downloads"), filepath.Join(cacheDir, "downloads")},
+		{oldVersions, newVersions},
+		{oldBin, newBin},
+	}
+	for _, m := range moves {
+		if err := moveDir(m[0], m[1]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Installed binaries are symlinks into the versions directory
+	if stale := relinkVersions(configDir, oldVersions, newVersions); stale {
+		if err := os.Symlink(newVersions, oldVersions); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// ~/.autonomix/bin may be on PATH, keep it working
+	if _, err := os.Stat(newBin); err == nil {
+		if err := os.Symlink(newBin, oldBin); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	os.Remove(filepath.Join(legacy, "config.lock"))
+	os.Remove(filepath.Join(legacy, "cache"))
+	os.Remove(legacy)
+	return errors.Join(errs...)
+}
+
+// migrateLegacyConfig moves config.json and its backups, pointing binary
+// paths under the old bin directory at the new one.
+func migrateLegacyConfig(legacy, configDir, oldBin, newBin string) error {
+	matches, _ := filepath.Glob(filepath.Join(legacy, "config.json*"))
+	if len(matches) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+
+	for _, path := range matches {
+		if filepath.Base(path) != "config.json" {
+			if err := moveDir(path, filepath.Join(configDir, filepath.Base(path))); err != nil {
+				return err
+			}
+		}
+	}
+
+	path := filepath.Join(legacy, "config.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err == nil {
+		apps, _ := doc["apps"].([]any)
+		for _, entry := range apps {
+			app, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			if p, _ := app["binary_path"].(string); filepath.Dir(p) == oldBin {
+				app["binary_path"] = filepath.Join(newBin, filepath.Base(p))
+			}
+		}
+		if migrated, err := json.MarshalIndent(doc, "", "  "); err == nil {
+			data = migrated
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), data, fileMode); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// relinkVersions repoints installed symlinks from the old versions
+// directory to the new one. It reports whether any link could not be
+// updated, for example because it lives in a root-owned directory.
+func relinkVersions(configDir, oldVersions, newVersions string) bool {
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return false
+	}
+	var doc struct {
+		Apps []struct {
+			BinaryPath string `json:"binary_path"`
+		} `json:"apps"`
+	}
+	if json.Unmarshal(data, &doc) != nil {
+		return false
+	}
+
+	stale := false
+	for _, app := range doc.Apps {
+		link := app.BinaryPath
+		dest, err := os.Readlink(link)
+		if err != nil || !strings.HasPrefix(dest, oldVersions+string(filepath.Separator)) {
+			continue
+		}
+
+		target := filepath.Join(newVersions, strings.TrimPrefix(dest, oldVersions+string(filepath.Separator)))
+		tmp := link + ".autonomix-new"
+		os.Remove(tmp)
+		if os.Symlink(target, tmp) != nil || os.Rename(tmp, link) != nil {
+			os.Remove(tmp)
+			stale = true
+		}
+	}
+	return stale
+}
+
+// moveDir renames src to dst, creating dst's parent. A missing src is not
+// an error.
+func moveDir(src, dst string) error {
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("move %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
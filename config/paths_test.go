@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBaseDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	t.Setenv("XDG_CACHE_HOME", "relative/ignored")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	tests := []struct {
+		name string
+		get  func() (string, error)
+		want string
+	}{
+		{"config", GetConfigDir, filepath.Join(home, "xdg-config", "autonomix")},
+		{"cache", GetCacheDir, filepath.Join(home, ".cache", "autonomix")},
+		{"state", GetStateDir, filepath.Join(home, ".local", "state", "autonomix")},
+		{"data", GetDataDir, filepath.Join(home, ".local", "share", "autonomix")},
+	}
+	for _, tt := range tests {
+		got, err := tt.get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s dir = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	root := t.TempDir()
+	t.Setenv(HomeEnv, root)
+	if got, _ := GetDataDir(); got != filepath.Join(root, "data") {
+		t.Errorf("data dir under %s = %s", HomeEnv, got)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnv, "")
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(env, "")
+	}
+
+	legacy := filepath.Join(home, ".autonomix")
+	oldVersion := filepath.Join(legacy, "versions", "tool", "1.0.0", "tool")
+	mustWrite(t, oldVersion, "binary")
+	mustWrite(t, filepath.Join(legacy, "cache", "downloads", "index.json"), "[]")
+
+	userBin := filepath.Join(home, ".local", "bin")
+	if err := os.MkdirAll(userBin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(userBin, "tool")
+	if err := os.Symlink(oldVersion, link); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(legacy, "config.json"),
+		`{"apps":[{"name":"tool","repo_url":"https://github.com/o/tool","binary_path":"`+link+`"}]}`)
+
+	if err := migrateLegacy(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(home, ".config", "autonomix", "config.json"),
+		filepath.Join(home, ".cache", "autonomix", "downloads", "index.json"),
+		filepath.Join(home, ".local", "share", "autonomix", "versions", "tool", "1.0.0", "tool"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s after migration: %v", path, err)
+		}
+	}
+	if _, err := os.Lstat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy directory still exists: %v", err)
+	}
+
+	dest, err := os.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dest, filepath.Join(home, ".local", "share", "autonomix")) {
+		t.Errorf("binary still links to %s", dest)
+	}
+}
+
+func TestReadOnlyUsesLegacyInPlace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnv, "")
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(env, "")
+	}
+	SetReadOnly(true)
+	t.Cleanup(func() { SetReadOnly(false) })
+
+	legacy := filepath.Join(home, ".autonomix")
+	mustWrite(t, filepath.Join(legacy, "config.json"),
+		`{"apps":[{"name":"tool","repo_url":"https://github.com/o/tool"}]}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Apps) != 1 || cfg.Apps[0].Name != "tool" {
+		t.Errorf("read-only Load did not read the legacy config: %+v", cfg.Apps)
+	}
+	if got, _ := GetCacheDir(); got != filepath.Join(legacy, "cache") {
+		t.Errorf("cache dir = %s, want the legacy cache", got)
+	}
+	if got, _ := GetDataDir(); got != legacy {
+		t.Errorf("data dir = %s, want %s", got, legacy)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config")); !os.IsNotExist(err) {
+		t.Errorf("read-only run created the XDG config directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "config.json")); err != nil {
+		t.Errorf("legacy config was moved: %v", err)
+	}
+}
+
+func mustWrite(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Release channels
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+)
+
+// EscalationAuto picks the first installed tool of sudo, doas, run0 and
+// pkexec.
+const EscalationAuto = "auto"
+
+// Settings holds global preferences. Zero values mean "use the default",
+// so only settings the user changed are written to the config file.
+type Settings struct {
+	// InstallMethod is tried first for new installs: auto, package,
+	// homebrew or binary.
+	InstallMethod string `json:"install_method,omitempty"`
+
+	// InstallDir is where binaries are linked; empty picks ~/.local/bin or
+	// the autonomix bin directory.
+	InstallDir string `json:"install_dir,omitempty"`
+
+	// KeepVersions is how many versions of each binary are kept for
+	// rollback, including the active one.
+	KeepVersions int `json:"keep_versions,omitempty"`
+
+	// CheckInterval is how long a release check stays fresh, as a Go
+	// duration such as "24h".
+	CheckInterval string `json:"check_interval,omitempty"`
+
+	// Parallelism bounds concurrent GitHub requests.
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// GitHubToken authenticates API requests; GITHUB_TOKEN takes
+	// precedence when set.
+	GitHubToken string `json:"github_token,omitempty"`
+
+	// Channel is the default release channel: stable or prerelease.
+	Channel string `json:"channel,omitempty"`
+
+	// CacheMaxSize caps the download cache in bytes.
+	CacheMaxSize int64 `json:"cache_max_size,omitempty"`
+
+	// EscalationTool runs privileged commands: auto, sudo, doas, pkexec
+	// or run0.
+	EscalationTool string `json:"escalation_tool,omitempty"`
+}
+
+// DefaultSettings returns the settings used for anything left unset.
+func DefaultSettings() Settings {
+	return Settings{
+		InstallMethod:  "auto",
+		KeepVersions:   3,
+		CheckInterval:  "24h",
+		Parallelism:    4,
+		Channel:        ChannelStable,
+		CacheMaxSize:   1 << 30,
+		EscalationTool: EscalationAuto,
+	}
+}
+
+// WithDefaults returns s with every unset field filled from DefaultSettings.
+func (s Settings) WithDefaults() Settings {
+	d := DefaultSettings()
+	if s.InstallMethod == "" {
+		s.InstallMethod = d.InstallMethod
+	}
+	if s.KeepVersions <= 0 {
+		s.KeepVersions = d.KeepVersions
+	}
+	if s.CheckInterval == "" {
+		s.CheckInterval = d.CheckInterval
+	}
+	if s.Parallelism <= 0 {
+		s.Parallelism = d.Parallelism
+	}
+	if s.Channel == "" {
+		s.Channel = d.Channel
+	}
+	if s.CacheMaxSize <= 0 {
+		s.CacheMaxSize = d.CacheMaxSize
+	}
+	if s.EscalationTool == "" {
+		s.EscalationTool = d.EscalationTool
+	}
+	return s
+}
+
+// CheckEvery returns CheckInterval as a duration.
+func (s Settings) CheckEvery() time.Duration {
+	d, err := time.ParseDuration(s.WithDefaults().CheckInterval)
+	if err != nil {
+		d, _ = time.ParseDuration(DefaultSettings().CheckInterval)
+	}
+	return d
+}
+
+// ChannelFor returns the release channel app follows.
+func (s Settings) ChannelFor(app App) string {
+	if app.Channel != "" {
+		return app.Channel
+	}
+	return s.WithDefaults().Channel
+}
+
+// setting describes one key of the config command.
+type setting struct {
+	get    func(*Settings) string
+	set    func(*Settings, string) error
+	reset  func(*Settings)
+	secret bool
+}
+
+var settings = map[string]setting{
+	"install-method": {
+		get: func(s *Settings) string { return s.InstallMethod },
+		set: func(s *Settings, v string) error {
+			return oneOf(&s.InstallMethod, v, "auto", InstallMethodPackage, InstallMethodHomebrew, InstallMethodBinary)
+		},
+		reset: func(s *Settings) { s.InstallMethod = "" },
+	},
+	"install-dir": {
+		get: func(s *Settings) string { return s.InstallDir },
+		set: func(s *Settings, v string) error {
+			s.InstallDir = v
+			return nil
+		},
+		reset: func(s *Settings) { s.InstallDir = "" },
+	},
+	"keep-versions": {
+		get:   func(s *Settings) string { return strconv.Itoa(s.KeepVersions) },
+		set:   func(s *Settings, v string) error { return positive(&s.KeepVersions, v) },
+		reset: func(s *Settings) { s.KeepVersions = 0 },
+	},
+	"check-interval": {
+		get: func(s *Settings) string { return s.CheckInterval },
+		set: func(s *Settings, v string) error {
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				return fmt.Errorf("invalid duration %q", v)
+			}
+			s.CheckInterval = v
+			return nil
+		},
+		reset: func(s *Settings) { s.CheckInterval = "" },
+	},
+	"parallelism": {
+		get:   func(s *Settings) string { return strconv.Itoa(s.Parallelism) },
+		set:   func(s *Settings, v string) error { return positive(&s.Parallelism, v) },
+		reset: func(s *Settings) { s.Parallelism = 0 },
+	},
+	"github-token": {
+		get: func(s *Settings) string { return s.GitHubToken },
+		set: func(s *Settings, v string) error {
+			s.GitHubToken = v
+			return nil
+		},
+		reset:  func(s *Settings) { s.GitHubToken = "" },
+		secret: true,
+	},
+	"channel": {
+		get:   func(s *Settings) string { return s.Channel },
+		set:   func(s *Settings, v string) error { return oneOf(&s.Channel, v, ChannelStable, ChannelPrerelease) },
+		reset: func(s *Settings) { s.Channel = "" },
+	},
+	"cache-max-size": {
+		get: func(s *Settings) string { return strconv.FormatInt(s.CacheMaxSize, 10) },
+		set: func(s *Settings, v string) error {
+			n, err := ParseSize(v)
+			if err != nil {
+				return err
+			}
+			// Zero would read as unset and bring back the default
+			if n <= 0 {
+				return fmt.Errorf("invalid value %q, expected a size above zero", v)
+			}
+			s.CacheMaxSize = n
+			return nil
+		},
+		reset: func(s *Settings) { s.CacheMaxSize = 0 },
+	},
+	"escalation-tool": {
+		get: func(s *Settings) string { return s.EscalationTool },
+		set: func(s *Settings, v string) error {
+			return oneOf(&s.EscalationTool, v, EscalationAuto, "sudo", "doas", "pkexec", "run0")
+		},
+		reset: func(s *Settings) { s.EscalationTool = "" },
+	},
+}
+
+// SettingKeys lists the keys accepted by Get and Set, sorted.
+func SettingKeys() []string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the effective value of key, applying defaults.
+func (s Settings) Get(key string) (string, error) {
+	def, ok := settings[key]
+	if !ok {
+		return "", fmt.Errorf("unknown setting %q", key)
+	}
+	eff := s.WithDefaults()
+	return def.get(&eff), nil
+}
+
+// IsSet reports whether key has been changed from its default.
+func (s Settings) IsSet(key string) bool {
+	def, ok := settings[key]
+	if !ok {
+		return false
+	}
+	unset := s
+	def.reset(&unset)
+	return unset != s
+}
+
+// Validate checks every set value as Set would, for settings edited by
+// hand.
+func (s Settings) Validate() error {
+	var errs []error
+	for _, key := range SettingKeys() {
+		if !s.IsSet(key) {
+			continue
+		}
+		def := settings[key]
+		var check Settings
+		if err := def.set(&check, def.get(&s)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsSecret reports whether key holds a credential that should be masked.
+func IsSecret(key string) bool {
+	return settings[key].secret
+}
+
+// Set validates and stores value for key. An empty value restores the
+// default.
+func (s *Settings) Set(key, value string) error {
+	def, ok := settings[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if value == "" {
+		def.reset(s)
+		return nil
+	}
+	return def.set(s, value)
+}
+
+func oneOf(dst *string, v string, allowed ...string) error {
+	for _, a := range allowed {
+		if v == a {
+			*dst = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q, expected one of: %s", v, strings.Join(allowed, ", "))
+}
+
+func positive(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid value %q, expected a positive number", v)
+	}
+	*dst = n
+	return nil
+}
+
+// ParseSize parses sizes such as "500MB", "2G" or "1048576".
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+
+	mult := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return int64(n * float64(mult)), nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import "testing"
+
+func TestSettingsSetAndGet(t *testing.T) {
+	var s Settings
+	if got, _ := s.Get("keep-versions"); got != "3" {
+		t.Errorf("default keep-versions = %s, want 3", got)
+	}
+
+	if err := s.Set("keep-versions", "5"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get("keep-versions"); got != "5" {
+		t.Errorf("keep-versions = %s, want 5", got)
+	}
+	if err := s.Set("keep-versions", ""); err != nil {
+		t.Fatal(err)
+	}
+	if s.KeepVersions != 0 {
+		t.Errorf("empty value should reset keep-versions, got %d", s.KeepVersions)
+	}
+
+	for key, value := range map[string]string{
+		"keep-versions":  "none",
+		"channel":        "nightly",
+		"check-interval": "soon",
+		"install-method": "snap",
+		"cache-max-size": "0",
+		"bogus":          "1",
+	} {
+		if err := s.Set(key, value); err == nil {
+			t.Errorf("Set(%s, %s) should fail", key, value)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":   1024,
+		"500MB":  500 << 20,
+		"2G":     2 << 30,
+		"1.5GiB": 3 << 29,
+	}
+	for in, want := range tests {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Error("expected error for invalid size")
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	s := Settings{Channel: ChannelPrerelease, KeepVersions: 2}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("valid settings rejected: %v", err)
+	}
+	if !s.IsSet("channel") || s.IsSet("parallelism") {
+		t.Error("IsSet does not reflect changed keys")
+	}
+
+	s.Channel = "nightly"
+	if err := s.Validate(); err == nil {
+		t.Error("expected error for invalid channel")
+	}
+}
@@ -7,10 +7,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tim/autonomix-cli/config"
 	"github.com/tim/autonomix-cli/pkg/cli"
+	"github.com/tim/autonomix-cli/pkg/selfupdate"
 	"github.com/tim/autonomix-cli/tui"
 )
 
-const SelfRepoURL = "https://github.com/timappledotcom/autonomix-cli"
+const SelfRepoURL = selfupdate.RepoURL
 
 var version = "v0.3.0"
 
@@ -20,33 +21,25 @@ func main() {
 		return
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		os.Exit(1)
-	}
-
 	// Ensure self is tracked
-	tracked := false
-	for i, app := range cfg.Apps {
-		if app.RepoURL == SelfRepoURL {
-			tracked = true
-			if app.Version != version {
-				cfg.Apps[i].Version = version
-			}
-			break
+	cfg, err := config.Update(func(cfg *config.Config) error {
+		if app := cfg.FindApp(SelfRepoURL); app != nil {
+			app.Version = version
+			return nil
 		}
-	}
-	if !tracked {
 		cfg.Apps = append(cfg.Apps, config.App{
 			Name:    "autonomix-cli",
 			RepoURL: SelfRepoURL,
 			Version: version,
 		})
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
 	}
-	config.Save(cfg)
 
-	p := tea.NewProgram(tui.NewModel(cfg), tea.WithAltScreen())
+	p := tea.NewProgram(tui.NewModel(cfg, version), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
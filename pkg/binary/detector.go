@@ -15,6 +15,7 @@ const (
 	UserPath
 	Homebrew
 	AutonomixPath
+	CustomPath // the install-dir setting
 )
 
 type BinaryAsset struct {
@@ -2,12 +2,40 @@ package binary
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/privilege"
 )
 
+// Options are the settings binary installs follow.
+type Options struct {
+	// InstallDir is where Auto and CustomPath installs go, the install-dir
+	// setting; empty searches the default directories.
+	InstallDir string
+
+	// KeepVersions is how many versions of each binary are kept for
+	// rollback, including the active one.
+	KeepVersions int
+
+	// Escalator installs into paths that need root.
+	Escalator privilege.Escalator
+}
+
+// NewOptions returns the Options s selects.
+func NewOptions(s config.Settings) Options {
+	s = s.WithDefaults()
+	return Options{
+		InstallDir:   s.InstallDir,
+		KeepVersions: s.KeepVersions,
+		Escalator:    privilege.Escalator{Tool: s.EscalationTool},
+	}
+}
+
 type InstallResult struct {
 	Path         string
 	Method       InstallMethod
@@ -15,25 +43,30 @@ type InstallResult struct {
 	InPath       bool
 }
 
-// InstallBinary installs binary to system
-func InstallBinary(binaryPath, appName string, method InstallMethod) (*InstallResult, error) {
-	targetPath, selectedMethod, requiresSudo := determineInstallPath(appName, method)
+// InstallBinary installs binary to system. The binary is kept under
+// VersionsDir, so earlier versions stay available for Rollback, and the
+// install path becomes a symlink to it; install paths that need privilege
+// get a root-owned copy instead.
+func InstallBinary(binaryPath, appName, version string, method InstallMethod, opts Options) (*InstallResult, error) {
+	targetPath, selectedMethod, requiresSudo := determineInstallPath(appName, method, opts.InstallDir)
 
-	if requiresSudo {
-		if err := installWithSudo(binaryPath, targetPath); err != nil {
-			return nil, err
-		}
-	} else {
+	versionPath, err := storeVersion(binaryPath, appName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !requiresSudo {
 		dir := filepath.Dir(targetPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return nil, err
 		}
-		if err := copyBinary(binaryPath, targetPath); err != nil {
-			return nil, err
-		}
 	}
+	if err := activateVersion(versionPath, targetPath, requiresSudo, opts.Escalator); err != nil {
+		return nil, err
+	}
+	pruneVersions(appName, targetPath, opts.KeepVersions)
 
-	inPath := isInPath(filepath.Dir(targetPath))
+	inPath := InPath(filepath.Dir(targetPath))
 
 	return &InstallResult{
 		Path:         targetPath,
@@ -43,13 +76,28 @@ func InstallBinary(binaryPath, appName string, method InstallMethod) (*InstallRe
 	}, nil
 }
 
-func determineInstallPath(appName string, method InstallMethod) (string, InstallMethod, bool) {
+// TargetPath reports where InstallBinary would install appName with method
+// and installDir, and whether writing there needs sudo.
+func TargetPath(appName string, method InstallMethod, installDir string) (string, bool) {
+	path, _, sudo := determineInstallPath(appName, method, installDir)
+	return path, sudo
+}
+
+func determineInstallPath(appName string, method InstallMethod, installDir string) (string, InstallMethod, bool) {
 	home, _ := os.UserHomeDir()
 
+	if method == Auto || method == CustomPath {
+		// 0. The configured install directory
+		if installDir != "" {
+			target := filepath.Join(expandHome(installDir), appName)
+			return target, CustomPath, RequiresSudo(target)
+		}
+	}
+
 	if method == Auto {
 		// 1. ~/.local/bin (if exists and is in PATH)
 		localBin := filepath.Join(home, ".local", "bin")
-		if _, err := os.Stat(localBin); err == nil && isInPath(localBin) {
+		if _, err := os.Stat(localBin); err == nil && InPath(localBin) {
 			return filepath.Join(localBin, appName), UserPath, false
 		}
 
@@ -62,14 +110,58 @@ func determineInstallPath(appName string, method InstallMethod) (string, Install
 		return filepath.Join("/usr/local/bin", appName), SystemPath, true
 	case UserPath:
 		return filepath.Join(home, ".local", "bin", appName), UserPath, false
-	case AutonomixPath:
-		return filepath.Join(home, ".autonomix", "bin", appName), AutonomixPath, false
 	}
 
-	return filepath.Join(home, ".autonomix", "bin", appName), AutonomixPath, false
+	return filepath.Join(AutonomixBinDir(), appName), AutonomixPath, false
+}
+
+// expandHome resolves a leading ~ in a configured path.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+// AutonomixBinDir is the fallback install directory owned by autonomix.
+func AutonomixBinDir() string {
+	dir, _ := config.GetDataDir()
+	return filepath.Join(dir, "bin")
+}
+
+// MethodForPath reports which install method places binaries at path,
+// given the install-dir setting, so updates land where the previous
+// version was installed.
+func MethodForPath(path, installDir string) InstallMethod {
+	home, _ := os.UserHomeDir()
+	switch filepath.Dir(path) {
+	case "/usr/local/bin":
+		return SystemPath
+	case filepath.Join(home, ".local", "bin"):
+		return UserPath
+	case AutonomixBinDir():
+		return AutonomixPath
+	}
+	if installDir != "" && filepath.Dir(path) == filepath.Clean(expandHome(installDir)) {
+		return CustomPath
+	}
+	return Auto
+}
+
+// BinDirs returns the directories binaries are linked into: ~/.local/bin,
+// the install-dir setting when set, and the autonomix bin directory.
+func BinDirs(installDir string) []string {
+	home, _ := os.UserHomeDir()
+	dirs := []string{filepath.Join(home, ".local", "bin")}
+	if installDir != "" {
+		dirs = append(dirs, filepath.Clean(expandHome(installDir)))
+	}
+	return append(dirs, AutonomixBinDir())
 }
 
-func isInPath(dir string) bool {
+// InPath reports whether dir is listed in PATH.
+func InPath(dir string) bool {
 	pathEnv := os.Getenv("PATH")
 	paths := strings.Split(pathEnv, ":")
 	for _, p := range paths {
@@ -80,31 +172,85 @@ func isInPath(dir string) bool {
 	return false
 }
 
+// copyBinary atomically replaces dst with a copy of src. The data is
+// written to a temporary file in the destination directory, synced and
+// renamed over dst, so a running binary is never modified in place and an
+// interrupted copy never leaves a truncated file behind.
 func copyBinary(src, dst string) error {
-	input, err := os.ReadFile(src)
+	in, err := os.Open(src)
 	if err != nil {
 		return err
 	}
+	defer in.Close()
+
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(dst); err == nil {
+		mode = info.Mode().Perm() | 0111
+	}
 
-	if err := os.WriteFile(dst, input, 0755); err != nil {
+	dir := filepath.Dir(dst)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".autonomix-*")
+	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
 
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes directory metadata so a completed rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Some filesystems do not support syncing directories; that is not fatal
+	d.Sync()
 	return nil
 }
 
-func installWithSudo(binaryPath, targetPath string) error {
-	cmd := exec.Command("sudo", "cp", binaryPath, targetPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+// ReplaceFile atomically replaces dst with src, escalating through esc when
+// the destination directory is not writable. The privileged path installs
+// into a staging name next to dst and renames it over the target.
+func ReplaceFile(src, dst string, esc privilege.Escalator) error {
+	if !RequiresSudo(dst) {
+		return copyBinary(src, dst)
+	}
+	return installPrivileged(src, dst, esc)
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("sudo install failed: %w", err)
+// installPrivileged installs a root-owned copy of src at dst through esc.
+func installPrivileged(src, dst string, esc privilege.Escalator) error {
+	staging := dst + ".autonomix-new"
+	if err := esc.Run("install", "-m", "755", src, staging); err != nil {
+		return fmt.Errorf("privileged install failed: %w", err)
 	}
 
-	cmd = exec.Command("sudo", "chmod", "755", targetPath)
-	return cmd.Run()
+	if err := esc.Run("mv", "-f", staging, dst); err != nil {
+		esc.RunQuiet("rm", "-f", staging)
+		return fmt.Errorf("privileged rename failed: %w", err)
+	}
+	return nil
 }
 
 // VerifyInstallation verifies binary is accessible
@@ -0,0 +1,49 @@
+package binary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyBinary_ReplacesOpenTarget(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "new")
+	dst := filepath.Join(dir, "tool")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold the old file open, as a running process would
+	held, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	if err := copyBinary(src, dst); err != nil {
+		t.Fatalf("copyBinary returned error: %v", err)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if string(data) != "new" {
+		t.Errorf("dst contains %q, want %q", data, "new")
+	}
+	old := make([]byte, 3)
+	if _, err := held.Read(old); err != nil || string(old) != "old" {
+		t.Errorf("open handle should still see the old contents, got %q", old)
+	}
+
+	info, _ := os.Stat(dst)
+	if info.Mode().Perm() != 0750|0111 {
+		t.Errorf("mode = %v, want existing mode preserved and executable", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected no leftover temp files, found %d entries", len(entries))
+	}
+}
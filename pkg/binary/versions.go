@@ -0,0 +1,207 @@
+package binary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+)
+
+// Version is an installed version of a binary kept for rollback.
+type Version struct {
+	Version   string
+	Path      string
+	Installed time.Time
+}
+
+// VersionsDir returns the directory holding every kept version of appName.
+func VersionsDir(appName string) string {
+	dir, _ := config.GetDataDir()
+	return filepath.Join(dir, "versions", appName)
+}
+
+// ListVersions returns the kept versions of appName, newest first.
+func ListVersions(appName string) ([]Version, error) {
+	dir := VersionsDir(appName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name(), appName)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, Version{Version: e.Name(), Path: path, Installed: info.ModTime()})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Installed.After(versions[j].Installed)
+	})
+	return versions, nil
+}
+
+// ActiveVersion reports which kept version is installed at targetPath:
+// the version it links to, or for a copied binary the kept version with
+// the same contents.
+func ActiveVersion(targetPath string) string {
+	if dest, err := os.Readlink(targetPath); err == nil {
+		return filepath.Base(filepath.Dir(dest))
+	}
+
+	digest, err := fileDigest(targetPath)
+	if err != nil {
+		return ""
+	}
+	versions, _ := ListVersions(filepath.Base(targetPath))
+	for _, v := range versions {
+		if d, err := fileDigest(v.Path); err == nil && d == digest {
+			return v.Version
+		}
+	}
+	return ""
+}
+
+// Rollback switches targetPath to a kept version of appName, escalating
+// through esc when targetPath needs root. An empty version selects the
+// newest version other than the active one.
+func Rollback(appName, targetPath, version string, esc privilege.Escalator) (string, error) {
+	versions, err := ListVersions(appName)
+	if err != nil {
+		return "", err
+	}
+
+	active := ActiveVersion(targetPath)
+	var selected *Version
+	for i, v := range versions {
+		if (version == "" && v.Version != active) || v.Version == version {
+			selected = &versions[i]
+			break
+		}
+	}
+
+	if selected == nil {
+		if version == "" {
+			return "", fmt.Errorf("no previous version of %s to roll back to", appName)
+		}
+		return "", fmt.Errorf("version %s of %s is not available", version, appName)
+	}
+
+	if err := activateVersion(selected.Path, targetPath, RequiresSudo(targetPath), esc); err != nil {
+		return "", err
+	}
+
+	// Mark as most recently installed so pruning keeps it
+	now := time.Now()
+	os.Chtimes(selected.Path, now, now)
+	return selected.Version, nil
+}
+
+// RemoveVersions deletes every kept version of appName.
+func RemoveVersions(appName string) error {
+	return os.RemoveAll(VersionsDir(appName))
+}
+
+// RequiresSudo reports whether writing to path needs elevated privileges.
+func RequiresSudo(path string) bool {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, ".autonomix-write-test-*")
+	if err != nil {
+		return os.IsPermission(err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return false
+}
+
+// storeVersion copies binaryPath into the versions directory.
+func storeVersion(binaryPath, appName, version string) (string, error) {
+	if version == "" {
+		version = time.Now().Format("20060102150405")
+	}
+
+	dir := filepath.Join(VersionsDir(appName), version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(dir, appName)
+	if err := copyBinary(binaryPath, dest); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	os.Chtimes(dest, now, now)
+	return dest, nil
+}
+
+// pruneVersions removes the oldest kept versions beyond keep, never touching the one targetPath links to.
+func pruneVersions(appName, targetPath string, keep int) {
+	versions, err := ListVersions(appName)
+	if err != nil {
+		return
+	}
+	active := ActiveVersion(targetPath)
+	kept := 0
+	for _, v := range versions {
+		if v.Version == active || kept < keep {
+			kept++
+			continue
+		}
+		os.RemoveAll(filepath.Dir(v.Path))
+	}
+}
+
+// activateVersion makes versionPath the binary at targetPath. In a
+// directory the user can write, targetPath becomes a symlink into the
+// versions directory, created under a temporary name and renamed into
+// place so the switch is atomic. A target that needs privilege gets a
+// root-owned copy instead, so a system PATH entry never resolves to a file
+// in the user's home that the user could modify.
+func activateVersion(versionPath, targetPath string, sudo bool, esc privilege.Escalator) error {
+	if sudo {
+		return installPrivileged(versionPath, targetPath, esc)
+	}
+
+	tmp := targetPath + ".autonomix-new"
+	os.Remove(tmp)
+	if err := os.Symlink(versionPath, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, targetPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// fileDigest returns the hex encoded SHA-256 of the file at path.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,101 @@
+package binary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+)
+
+func TestInstallBinary_Rollback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AUTONOMIX_HOME", home)
+
+	install := func(version, content string) *InstallResult {
+		t.Helper()
+		src := filepath.Join(t.TempDir(), "tool")
+		if err := os.WriteFile(src, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		res, err := InstallBinary(src, "tool", version, AutonomixPath, NewOptions(config.Settings{}))
+		if err != nil {
+			t.Fatalf("InstallBinary(%s) returned error: %v", version, err)
+		}
+		return res
+	}
+
+	install("1.0.0", "one")
+	res := install("1.1.0", "two")
+
+	if got := ActiveVersion(res.Path); got != "1.1.0" {
+		t.Fatalf("ActiveVersion = %s, want 1.1.0", got)
+	}
+
+	version, err := Rollback("tool", res.Path, "", privilege.Escalator{})
+	if err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	if version != "1.0.0" {
+		t.Errorf("Rollback selected %s, want 1.0.0", version)
+	}
+
+	data, err := os.ReadFile(res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one" {
+		t.Errorf("after rollback binary contains %q, want %q", data, "one")
+	}
+
+	if _, err := Rollback("tool", res.Path, "9.9.9", privilege.Escalator{}); err == nil {
+		t.Error("expected error rolling back to unknown version")
+	}
+}
+
+func TestInstallBinary_PrunesOldVersions(t *testing.T) {
+	t.Setenv("AUTONOMIX_HOME", t.TempDir())
+
+	for _, v := range []string{"1", "2", "3", "4", "5"} {
+		src := filepath.Join(t.TempDir(), "tool")
+		os.WriteFile(src, []byte(v), 0755)
+		if _, err := InstallBinary(src, "tool", v, AutonomixPath, NewOptions(config.Settings{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := ListVersions("tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keep := config.DefaultSettings().KeepVersions; len(versions) != keep {
+		t.Errorf("kept %d versions, want %d", len(versions), keep)
+	}
+}
+
+func TestActiveVersion_Copied(t *testing.T) {
+	t.Setenv("AUTONOMIX_HOME", t.TempDir())
+
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		src := filepath.Join(t.TempDir(), "tool")
+		os.WriteFile(src, []byte(v), 0755)
+		if _, err := InstallBinary(src, "tool", v, AutonomixPath, NewOptions(config.Settings{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Privileged targets hold a copy of the kept version, not a link
+	target := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(target, []byte("1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ActiveVersion(target); got != "1.0.0" {
+		t.Errorf("ActiveVersion = %q, want 1.0.0", got)
+	}
+
+	os.WriteFile(target, []byte("unknown"), 0755)
+	if got := ActiveVersion(target); got != "" {
+		t.Errorf("ActiveVersion of an unkept binary = %q, want empty", got)
+	}
+}
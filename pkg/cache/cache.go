@@ -0,0 +1,345 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+// Entry describes a cached download. Blobs are stored by content digest, so
+// several URLs serving identical bytes share one file on disk.
+type Entry struct {
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Added    time.Time `json:"added"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Cache is a content-addressed store of downloaded release assets with
+// least-recently-used eviction once MaxSize is exceeded.
+type Cache struct {
+	Dir     string
+	MaxSize int64
+}
+
+// Open returns the cache under the autonomix cache directory, capped at
+// maxSize bytes, normally the cache-max-size setting.
+func Open(maxSize int64) (*Cache, error) {
+	dir, err := config.GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{Dir: filepath.Join(dir, "downloads"), MaxSize: maxSize}, nil
+}
+
+// Path returns where the blob for entry lives. The asset name is kept so
+// installers can still recognise the file type by extension.
+func (c *Cache) Path(e Entry) string {
+	return filepath.Join(c.Dir, "blobs", e.Digest, e.Name)
+}
+
+// Lookup returns the cached file for url. When digest is non-empty the
+// entry must also match it.
+func (c *Cache) Lookup(url, digest string) (string, bool) {
+	var path string
+	err := c.withLock(true, func() error {
+		entries, err := c.load()
+		if err != nil {
+			return err
+		}
+
+		for i, e := range entries {
+			if e.URL != url || (digest != "" && !strings.EqualFold(e.Digest, digest)) {
+				continue
+			}
+			if _, err := os.Stat(c.Path(e)); err != nil {
+				return err
+			}
+			path = c.Path(e)
+			entries[i].LastUsed = time.Now()
+			c.save(entries)
+			return nil
+		}
+		return nil
+	})
+	return path, err == nil && path != ""
+}
+
+// Store moves the file at src into the cache as the download of url and
+// evicts old entries if the cache grows beyond its cap.
+func (c *Cache) Store(url, name, src string) (*Entry, error) {
+	digest, size, err := fileDigest(src)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := Entry{URL: url, Name: filepath.Base(name), Digest: digest, Size: size, Added: now, LastUsed: now}
+	dest := c.Path(entry)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	if err := moveFile(src, dest); err != nil {
+		return nil, err
+	}
+
+	err = c.withLock(true, func() error {
+		entries, err := c.load()
+		if err != nil {
+			return err
+		}
+		filtered := entries[:0]
+		for _, e := range entries {
+			if e.URL != url {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = append(filtered, entry)
+
+		entries, _ = c.evict(entries, url)
+		return c.save(entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns cached entries, most recently used first.
+func (c *Cache) List() ([]Entry, error) {
+	var entries []Entry
+	err := c.withLock(false, func() error {
+		var err error
+		entries, err = c.load()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Prune drops index entries whose files are gone, removes unreferenced
+// blobs and evicts least recently used entries down to MaxSize.
+func (c *Cache) Prune() ([]Entry, error) {
+	var removed []Entry
+	err := c.withLock(true, func() error {
+		entries, err := c.load()
+		if err != nil {
+			return err
+		}
+
+		present := entries[:0]
+		for _, e := range entries {
+			if _, err := os.Stat(c.Path(e)); err != nil {
+				removed = append(removed, e)
+				continue
+			}
+			present = append(present, e)
+		}
+
+		kept, evicted := c.evict(present, "")
+		removed = append(removed, evicted...)
+		if err := c.save(kept); err != nil {
+			return err
+		}
+
+		// Remove blobs no entry refers to anymore
+		referenced := make(map[string]bool)
+		for _, e := range kept {
+			referenced[e.Digest] = true
+		}
+		blobs, _ := os.ReadDir(filepath.Join(c.Dir, "blobs"))
+		for _, b := range blobs {
+			if !referenced[b.Name()] {
+				os.RemoveAll(filepath.Join(c.Dir, "blobs", b.Name()))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// Clear removes every cached download.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// Size returns the total size of the unique blobs in entries.
+func Size(entries []Entry) int64 {
+	seen := make(map[string]bool)
+	var total int64
+	for _, e := range entries {
+		if !seen[e.Digest] {
+			seen[e.Digest] = true
+			total += e.Size
+		}
+	}
+	return total
+}
+
+// evict removes least recently used entries until the cache fits MaxSize.
+// The entry for keepURL is never evicted.
+func (c *Cache) evict(entries []Entry, keepURL string) ([]Entry, []Entry) {
+	if c.MaxSize <= 0 || Size(entries) <= c.MaxSize {
+		return entries, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+
+	var evicted []Entry
+	kept := append([]Entry(nil), entries...)
+	for i := 0; i < len(kept) && Size(kept) > c.MaxSize; {
+		if kept[i].URL == keepURL {
+			i++
+			continue
+		}
+		evicted = append(evicted, kept[i])
+		kept = append(kept[:i], kept[i+1:]...)
+	}
+
+	// Only delete blobs no remaining entry shares
+	for _, e := range evicted {
+		shared := false
+		for _, k := range kept {
+			if k.Digest == e.Digest {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			os.RemoveAll(filepath.Join(c.Dir, "blobs", e.Digest))
+		}
+	}
+	return kept, evicted
+}
+
+func (c *Cache) indexPath() string {
+	return filepath.Join(c.Dir, "index.json")
+}
+
+// withLock runs fn while holding the advisory lock on index.lock, shared
+// for readers and exclusive for writers, so processes installing at the
+// same time do not lose each other's index updates.
+func (c *Cache) withLock(exclusive bool, fn func() error) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(c.Dir, "index.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, exclusive); err != nil {
+		return fmt.Errorf("failed to lock cache index: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+func (c *Cache) load() ([]Entry, error) {
+	data, err := os.ReadFile(c.indexPath())
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("corrupt cache index: %w", err)
+	}
+	return entries, nil
+}
+
+// save replaces the index through a temporary file in the cache directory.
+// Callers must hold the lock.
+func (c *Cache) save(entries []Entry) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, "index.json.tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.indexPath())
+}
+
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// moveFile renames src to dst, copying when they are on different filesystems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
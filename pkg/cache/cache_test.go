@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeTemp(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "download")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStoreAndLookup(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), MaxSize: 1 << 30}
+
+	entry, err := c.Store("https://example.com/tool.tar.gz", "tool.tar.gz", writeTemp(t, "payload"))
+	if err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+
+	path, ok := c.Lookup("https://example.com/tool.tar.gz", "")
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if !strings.HasSuffix(path, "tool.tar.gz") {
+		t.Errorf("cached path %s should keep the asset name", path)
+	}
+	if _, ok := c.Lookup("https://example.com/tool.tar.gz", entry.Digest); !ok {
+		t.Error("expected cache hit with matching digest")
+	}
+	if _, ok := c.Lookup("https://example.com/tool.tar.gz", "deadbeef"); ok {
+		t.Error("expected cache miss with mismatched digest")
+	}
+}
+
+func TestStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), MaxSize: 10}
+
+	c.Store("https://example.com/a", "a", writeTemp(t, "aaaaaa"))
+	c.Store("https://example.com/b", "b", writeTemp(t, "bbbbbb"))
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://example.com/b" {
+		t.Fatalf("expected only the newest entry to remain, got %+v", entries)
+	}
+	if _, ok := c.Lookup("https://example.com/a", ""); ok {
+		t.Error("evicted entry should not be found")
+	}
+}
+
+func TestStore_ConcurrentWritersKeepAllEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		src := writeTemp(t, fmt.Sprintf("payload %d", i))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Separate Cache values, as separate processes would have
+			c := &Cache{Dir: dir, MaxSize: 1 << 30}
+			if _, err := c.Store(fmt.Sprintf("https://example.com/%d", i), "tool", src); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := (&Cache{Dir: dir}).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != writers {
+		t.Errorf("index has %d entries, want %d", len(entries), writers)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cache
+
+import "os"
+
+// lockFile is a no-op where flock is unavailable.
+func lockFile(f *os.File, exclusive bool) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
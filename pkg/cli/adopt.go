@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// handleAdopt tracks tools that are already installed, without
+// reinstalling them. With no arguments it scans the system and asks about
+// each match; "adopt <name> <repo>" tracks one tool the scan cannot match.
+func handleAdopt(args []string) {
+	fs := flag.NewFlagSet("adopt", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Track every match without asking")
+	fs.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Scanning installed packages and PATH...")
+	items := system.Inventory()
+
+	var candidates []manager.Candidate
+	switch fs.NArg() {
+	case 0:
+		candidates = manager.FindCandidates(items, cfg)
+	case 2:
+		c, ok := findInstalled(items, fs.Arg(0))
+		if !ok {
+			fmt.Printf("Error: %s is not installed\n", fs.Arg(0))
+			os.Exit(1)
+		}
+		c.RepoURL = manager.CleanRepoURL(fs.Arg(1))
+		c.Match = manager.MatchUser
+		candidates = []manager.Candidate{c}
+		*yes = true
+	default:
+		fmt.Println("Error: usage: autonomix-cli adopt [--yes] | adopt <name> <repo-url>")
+		os.Exit(1)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println("Nothing to adopt")
+		return
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	adopted, failed := 0, 0
+	for _, c := range candidates {
+		item := c.Installed
+		where := string(item.Source)
+		if item.Path != "" {
+			where = item.Path
+		}
+		fmt.Printf("%s %s (%s) -> %s\n", item.Name, item.Version, where, c.RepoURL)
+
+		if dryRun {
+			continue
+		}
+		if !*yes && !confirm(stdin, "Track it?") {
+			continue
+		}
+		app, err := manager.AdoptApp(c)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", item.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ Tracking %s (%s)\n", app.Name, app.InstallMethod)
+		adopted++
+	}
+
+	if !dryRun {
+		fmt.Printf("\n%d app(s) adopted\n", adopted)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// findInstalled returns the first installed tool named name, preferring
+// packages over executables in PATH as Inventory lists them.
+func findInstalled(items []system.Installed, name string) (manager.Candidate, bool) {
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			return manager.Candidate{Installed: item}, true
+		}
+	}
+	return manager.Candidate{}, false
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to yes.
+func confirm(r *bufio.Reader, question string) bool {
+	fmt.Printf("%s [Y/n] ", question)
+	answer, err := r.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/manager"
+)
+
+// handleAutoUpdate installs the upgrades each app's update policy permits,
+// for all apps or the ones named. It never prompts, so it can run
+// unattended; every app that is not updated is listed with the reason.
+func handleAutoUpdate(args []string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	apps := cfg.Apps
+	if len(args) > 0 {
+		apps = nil
+		for _, name := range args {
+			app := cfg.FindAppByName(name)
+			if app == nil {
+				fmt.Printf("Error: %s not found\n", name)
+				os.Exit(1)
+			}
+			apps = append(apps, *app)
+		}
+	}
+
+	updated, failed := 0, 0
+	for _, app := range apps {
+		rel, plan, skip, err := manager.PlanAutoUpdate(app, cfg.Settings)
+		if rel != nil && !dryRun {
+			if _, recordErr := manager.RecordLatest(app.RepoURL, rel.TagName); recordErr != nil && err == nil {
+				err = recordErr
+			}
+		}
+		switch {
+		case err != nil:
+			fmt.Printf("✗ %s: %v\n", app.Name, err)
+			failed++
+			continue
+		case skip != "":
+			fmt.Printf("- %s: skipped, %s\n", app.Name, skip)
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("Would update %s from %s to %s\n", app.Name, app.Version, rel.TagName)
+			printPlan(plan, nil)
+			continue
+		}
+
+		fmt.Printf("Updating %s from %s to %s...\n", app.Name, app.Version, rel.TagName)
+		if _, err := manager.InstallApp(rel, app, binary.Auto, cfg.Settings); err != nil {
+			fmt.Printf("✗ %s: %v\n", app.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s %s\n", app.Name, rel.TagName)
+		updated++
+	}
+
+	if !dryRun {
+		fmt.Printf("\n%d app(s) updated, %d failed\n", updated, failed)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
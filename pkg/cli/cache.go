@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/cache"
+	"github.com/tim/autonomix-cli/pkg/download"
+)
+
+func handleCache(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: usage: autonomix-cli cache list|prune|clear|max-size")
+		os.Exit(1)
+	}
+
+	c, err := cache.Open(loadSettings().WithDefaults().CacheMaxSize)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		handleCacheList(c)
+	case "prune":
+		handleCachePrune(c, args[1:])
+	case "clear":
+		if err := c.Clear(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✓ Cleared download cache")
+	case "max-size":
+		handleCacheMaxSize(c, args[1:])
+	default:
+		fmt.Printf("Error: unknown cache command %q\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleCacheList(c *cache.Cache) {
+	entries, err := c.List()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Download cache is empty")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tLAST USED\tSHA256")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, download.FormatBytes(e.Size),
+			e.LastUsed.Format("2006-01-02 15:04"), shortDigest(e.Digest))
+	}
+	w.Flush()
+	fmt.Printf("\nTotal: %s of %s\n", download.FormatBytes(cache.Size(entries)), download.FormatBytes(c.MaxSize))
+}
+
+func handleCachePrune(c *cache.Cache, args []string) {
+	fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	maxSize := fs.String("max-size", "", "Evict down to this size (e.g. 500MB)")
+	fs.Parse(args)
+
+	if *maxSize != "" {
+		size, err := config.ParseSize(*maxSize)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		c.MaxSize = size
+	}
+
+	removed, err := c.Prune()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, e := range removed {
+		fmt.Printf("Removed %s\n", e.Name)
+	}
+	fmt.Printf("✓ Pruned %d cached download(s)\n", len(removed))
+}
+
+func handleCacheMaxSize(c *cache.Cache, args []string) {
+	if len(args) == 0 {
+		fmt.Println(download.FormatBytes(c.MaxSize))
+		return
+	}
+
+	cfg, err := config.Update(func(cfg *config.Config) error {
+		return cfg.Settings.Set("cache-max-size", args[0])
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Cache size limit set to %s\n", download.FormatBytes(cfg.Settings.CacheMaxSize))
+}
+
+// shortDigest abbreviates a SHA-256 for display.
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
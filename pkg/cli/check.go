@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/pkg/daemon"
+	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/notify"
+)
+
+// handleCheck checks tracked apps for new releases, for use by hand or
+// from the systemd timer.
+func handleCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	notifyFlag := fs.Bool("notify", false, "Send a desktop notification about new updates")
+	all := fs.Bool("all", false, "Check every app, not only those due per check-interval")
+	fs.Parse(args)
+
+	updates, checkErr := manager.CheckUpdates(*all, nil)
+	if checkErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", checkErr)
+	}
+
+	if len(updates) == 0 {
+		fmt.Println("All apps are up to date")
+	}
+	for _, app := range updates {
+		fmt.Printf("%s %s -> %s\n", app.Name, app.Version, app.Latest)
+	}
+
+	if *notifyFlag {
+		if _, err := manager.NotifyUpdates(notify.DBus{AppName: "autonomix-cli"}, updates); err != nil {
+			fmt.Printf("Error: failed to send notification: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if checkErr != nil {
+		os.Exit(1)
+	}
+}
+
+func handleDaemon(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: usage: autonomix-cli daemon install-timer|uninstall-timer")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "install-timer":
+		dir, err := daemon.InstallTimer()
+		if err != nil {
+			if dir != "" {
+				fmt.Printf("Wrote %s and %s to %s\n", daemon.ServiceName, daemon.TimerName, dir)
+			}
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Installed and started %s in %s\n", daemon.TimerName, dir)
+	case "uninstall-timer":
+		if err := daemon.UninstallTimer(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Removed %s\n", daemon.TimerName)
+	default:
+		fmt.Printf("Error: unknown daemon command %q\n", args[0])
+		os.Exit(1)
+	}
+}
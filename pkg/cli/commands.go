@@ -4,18 +4,35 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/tim/autonomix-cli/config"
 	"github.com/tim/autonomix-cli/pkg/binary"
-	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/installer"
 	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/privilege"
 )
 
+// dryRun is set by the global --dry-run flag. Commands that support it
+// resolve releases and select assets, then print what they would do
+// without downloading, installing, running installed binaries or saving
+// anything; the config is read-only.
+var dryRun bool
+
+var dryRunCommands = map[string]bool{"add": true, "update": true, "remove": true, "sync": true, "auto-update": true, "adopt": true}
+
 func HandleCommand(args []string, version string) {
+	args = parseGlobalFlags(args)
 	if len(args) == 0 {
-		return
+		fmt.Println("Error: command required, see autonomix-cli --help")
+		os.Exit(1)
+	}
+	if dryRun && !dryRunCommands[args[0]] {
+		fmt.Printf("Error: %s does not support --dry-run\n", args[0])
+		os.Exit(1)
 	}
 
 	cmd := args[0]
@@ -30,6 +47,42 @@ func HandleCommand(args []string, version string) {
 		handleRemove(args[1:])
 	case "clean":
 		handleClean()
+	case "set":
+		handleSet(args[1:])
+	case "sync":
+		handleSync(args[1:])
+	case "lock":
+		handleLock(args[1:])
+	case "install":
+		handleInstall(args[1:])
+	case "export":
+		handleExport(args[1:])
+	case "import":
+		handleImport(args[1:])
+	case "config":
+		handleConfig(args[1:])
+	case "cache":
+		handleCache(args[1:])
+	case "rollback":
+		handleRollback(args[1:])
+	case "history":
+		handleHistory(args[1:])
+	case "check":
+		handleCheck(args[1:])
+	case "auto-update":
+		handleAutoUpdate(args[1:])
+	case "daemon":
+		handleDaemon(args[1:])
+	case "info":
+		handleInfo()
+	case "doctor":
+		handleDoctor()
+	case "verify":
+		handleVerify(args[1:])
+	case "adopt":
+		handleAdopt(args[1:])
+	case "self-update":
+		handleSelfUpdate(args[1:], version)
 	case "--help", "-h":
 		printHelp(version)
 	case "--version", "-v":
@@ -37,6 +90,56 @@ func HandleCommand(args []string, version string) {
 	}
 }
 
+var warned bool
+
+// loadConfig reads the config and warns once about entries it had to
+// skip. Loading migrates an older config, so commands call
+// it themselves and --help and --version never touch the file.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if !warned {
+		for _, problem := range cfg.Problems() {
+			fmt.Fprintf(os.Stderr, "Warning: skipping config entry: %v\n", problem)
+		}
+		warned = true
+	}
+	return cfg, nil
+}
+
+// loadSettings returns the settings for commands that do not otherwise
+// read the config. If it cannot be read, the defaults are used.
+func loadSettings() config.Settings {
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Settings{}
+	}
+	return cfg.Settings
+}
+
+// parseGlobalFlags removes flags accepted by every command from args.
+func parseGlobalFlags(args []string) []string {
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(rest, args[i:]...)
+		}
+		if arg == "--dry-run" {
+			dryRun = true
+			config.SetReadOnly(true)
+			continue
+		}
+		if arg == "--non-interactive" {
+			privilege.SetNonInteractive(true)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest
+}
+
 func handleAdd(args []string) {
 	fs := flag.NewFlagSet("add", flag.ExitOnError)
 	brew := fs.Bool("brew", false, "Force Homebrew")
@@ -58,44 +161,39 @@ func handleAdd(args []string) {
 		method = binary.SystemPath
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+	if dryRun {
+		addDryRun(fs.Arg(0), method)
+		return
 	}
 
+	settings := loadSettings()
 	fmt.Printf("Adding %s...\n", fs.Arg(0))
-	res, err := manager.AddApp(cfg, fs.Arg(0))
+	res, err := manager.AddApp(fs.Arg(0), settings)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("✓ Tracked %s (Latest: %s)\n", res.App.Name, res.App.Latest)
-	if res.App.Version != "" {
+	if res.App.InstallStatus == config.StatusInstalled {
 		fmt.Printf("  Already installed: %s\n", res.App.Version)
-		app := &cfg.Apps[len(cfg.Apps)-1]
-		app.InstallStatus = config.StatusInstalled
-		config.Save(cfg)
 		return
 	}
 
 	// Now install
 	fmt.Printf("Installing...\n")
-	rel, err := github.GetLatestRelease(res.App.RepoURL)
+	rel, err := manager.LatestRelease(res.App, settings)
 	if err != nil {
 		fmt.Printf("Error fetching release: %v\n", err)
 		os.Exit(1)
 	}
 
-	app := &cfg.Apps[len(cfg.Apps)-1]
-	if err := manager.InstallApp(rel, app, method); err != nil {
-		config.Save(cfg)
+	app, err := manager.InstallApp(rel, res.App, method, settings)
+	if err != nil {
 		fmt.Printf("Error installing: %v\n", err)
 		os.Exit(1)
 	}
 
-	config.Save(cfg)
 	fmt.Printf("✓ Installed %s\n", app.Version)
 	if app.BinaryPath != "" {
 		fmt.Printf("  Path: %s\n", app.BinaryPath)
@@ -108,28 +206,42 @@ func handleUpdate(args []string) {
 		os.Exit(1)
 	}
 
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	for i, app := range cfg.Apps {
-		if app.Name == args[0] {
-			fmt.Printf("Updating %s...\n", args[0])
-			cfg.Apps[i].Version = app.Latest
-			config.Save(cfg)
-			fmt.Printf("✓ Updated to %s\n", app.Latest)
-			return
-		}
+	app := cfg.FindAppByName(args[0])
+	if app == nil {
+		fmt.Printf("Error: %s not found\n", args[0])
+		os.Exit(1)
+	}
+
+	if dryRun {
+		updateDryRun(*app, cfg.Settings)
+		return
+	}
+
+	fmt.Printf("Updating %s...\n", args[0])
+	rel, err := manager.LatestRelease(*app, cfg.Settings)
+	if err != nil {
+		fmt.Printf("Error fetching release: %v\n", err)
+		os.Exit(1)
 	}
+	manager.RecordLatest(app.RepoURL, rel.TagName)
 
-	fmt.Printf("Error: %s not found\n", args[0])
-	os.Exit(1)
+	updated, err := manager.InstallApp(rel, *app, binary.Auto, cfg.Settings)
+	if err != nil {
+		fmt.Printf("Error installing: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Updated to %s\n", updated.Version)
 }
 
 func handleList() {
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
@@ -161,30 +273,30 @@ func handleList() {
 }
 
 func handleClean() {
-	cfg, err := config.Load()
+	removed := 0
+	_, err := config.Update(func(cfg *config.Config) error {
+		filtered := []config.App{}
+		for _, app := range cfg.Apps {
+			if app.InstallStatus == config.StatusFailed {
+				fmt.Printf("Removing failed: %s\n", app.Name)
+				removed++
+			} else {
+				filtered = append(filtered, app)
+			}
+		}
+		cfg.Apps = filtered
+		return nil
+	})
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	removed := 0
-	filtered := []config.App{}
-	for _, app := range cfg.Apps {
-		if app.InstallStatus == config.StatusFailed {
-			fmt.Printf("Removing failed: %s\n", app.Name)
-			removed++
-		} else {
-			filtered = append(filtered, app)
-		}
-	}
-
 	if removed == 0 {
 		fmt.Println("No failed installations to clean")
 		return
 	}
 
-	cfg.Apps = filtered
-	config.Save(cfg)
 	fmt.Printf("✓ Cleaned %d failed installation(s)\n", removed)
 }
 
@@ -194,41 +306,100 @@ func handleRemove(args []string) {
 		os.Exit(1)
 	}
 
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	for i, app := range cfg.Apps {
-		if app.Name == args[0] {
-			uninstallApp(&app)
-			cfg.Apps = append(cfg.Apps[:i], cfg.Apps[i+1:]...)
-			config.Save(cfg)
-			fmt.Printf("✓ Removed %s\n", args[0])
-			return
-		}
+	app := cfg.FindAppByName(args[0])
+	if app == nil {
+		fmt.Printf("Error: %s not found\n", args[0])
+		os.Exit(1)
 	}
 
-	fmt.Printf("Error: %s not found\n", args[0])
-	os.Exit(1)
-}
+	if dryRun {
+		removeDryRun(*app)
+		return
+	}
 
-func uninstallApp(app *config.App) {
 	switch app.InstallMethod {
 	case config.InstallMethodHomebrew:
 		fmt.Printf("Uninstalling via Homebrew...\n")
-		cmd := exec.Command("brew", "uninstall", app.Name)
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("Warning: brew uninstall failed: %v\n", err)
-		}
 	case config.InstallMethodBinary:
 		if app.BinaryPath != "" {
 			fmt.Printf("Removing binary: %s\n", app.BinaryPath)
-			if err := os.Remove(app.BinaryPath); err != nil {
-				fmt.Printf("Warning: failed to remove: %v\n", err)
+		}
+	}
+	if err := manager.RemoveApp(*app, cfg.Settings); err != nil {
+		// RemoveApp untracks the app even when uninstalling fails
+		if cfg, loadErr := loadConfig(); loadErr != nil || cfg.FindApp(app.RepoURL) != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Warning: %v\n", err)
+	}
+	fmt.Printf("✓ Removed %s\n", args[0])
+}
+
+func handleSet(args []string) {
+	if len(args) < 2 {
+		fmt.Println("Error: usage: autonomix-cli set <app> <key> [value]")
+		os.Exit(1)
+	}
+
+	name, key := args[0], args[1]
+	value := strings.Join(args[2:], " ")
+
+	_, err := config.Update(func(cfg *config.Config) error {
+		app := cfg.FindAppByName(name)
+		if app == nil {
+			return fmt.Errorf("%s not found", name)
+		}
+
+		switch key {
+		case "asset-pattern":
+			if value != "" {
+				if _, err := installer.CompilePattern(value, app.Latest); err != nil {
+					return err
+				}
+			}
+			app.AssetPattern = value
+		case "pin":
+			app.Pin = value
+		case "channel":
+			if value != "" && value != config.ChannelStable && value != config.ChannelPrerelease {
+				return fmt.Errorf("unknown channel %q", value)
+			}
+			app.Channel = value
+		case "update-policy":
+			if value != "" && !slices.Contains(config.UpdatePolicies, value) {
+				return fmt.Errorf("unknown update policy %q, expected one of: %s", value, strings.Join(config.UpdatePolicies, ", "))
+			}
+			app.UpdatePolicy = value
+		case "version-args":
+			app.VersionArgs = strings.Fields(value)
+		case "version-pattern":
+			if value != "" {
+				if _, err := regexp.Compile(value); err != nil {
+					return fmt.Errorf("invalid version pattern: %w", err)
+				}
 			}
+			app.VersionPattern = value
+		default:
+			return fmt.Errorf("unknown setting %q", key)
 		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if value == "" {
+		fmt.Printf("✓ Cleared %s for %s\n", key, name)
+	} else {
+		fmt.Printf("✓ Set %s for %s: %s\n", key, name, value)
 	}
 }
 
@@ -242,6 +413,60 @@ USAGE:
   autonomix-cli list         List tracked apps
   autonomix-cli remove <app> Remove app
   autonomix-cli clean        Remove failed installations
+  autonomix-cli set <app> asset-pattern <pattern>
+                             Pin the release asset used for installs
+  autonomix-cli set <app> pin|channel [value]
+                             Hold an app at a release tag or channel
+  autonomix-cli set <app> update-policy <policy>
+                             manual, notify, auto-patch, auto-minor or auto
+  autonomix-cli set <app> version-args|version-pattern [value]
+                             How the installed version is read from the binary
+  autonomix-cli rollback <app> [version]
+                             Switch a binary install to a kept version
+  autonomix-cli history [-n N] [app]
+                             Show what autonomix did, for all apps or one
+  autonomix-cli check [--notify] [--all]
+                             Check for updates, optionally notifying
+  autonomix-cli auto-update [app...]
+                             Install the upgrades update policies permit
+  autonomix-cli daemon install-timer|uninstall-timer
+                             Run the check from a systemd user timer
+  autonomix-cli self-update  Update autonomix-cli itself
+  autonomix-cli info         Show the detected OS and package manager
+  autonomix-cli doctor       Diagnose problems that make installs fail
+  autonomix-cli verify [--fix] [app...]
+                             Check installed apps against the config
+  autonomix-cli adopt [--yes] | adopt <name> <repo-url>
+                             Track tools that are already installed
+  autonomix-cli sync [-f manifest]
+                             Make tracked apps match a manifest
+  autonomix-cli lock [-f manifest]
+                             Write autonomix.lock for a manifest
+  autonomix-cli install [-f manifest] [--frozen]
+                             Install exactly what autonomix.lock records
+  autonomix-cli export [-o file]
+                             Write tracked apps as a portable manifest
+  autonomix-cli import [--install] <file>
+                             Track (and install) the apps in a manifest
+  autonomix-cli config list|get|set|edit
+                             Show or change global settings
+  autonomix-cli cache list|prune|clear
+                             Manage the download cache
+  autonomix-cli cache max-size [size]
+                             Show or set the cache size limit
+
+FLAGS (sync):
+  -f <file>   Manifest (default autonomix.yaml or autonomix.toml)
+  --prune     Remove tracked apps not in the manifest
+
+FLAGS (install):
+  --frozen    Fail if the lockfile is missing or out of date
+
+FLAGS (self-update):
+  --check   Only check for a newer release
+
+FLAGS (rollback):
+  --list    List kept versions
 
 FLAGS (add):
   --brew    Homebrew
@@ -249,6 +474,10 @@ FLAGS (add):
   --system  System path
 
 OPTIONS:
+  --dry-run      Show what add, update, remove, sync, auto-update or adopt would do
+                 without downloading or changing anything
+  --non-interactive
+                 Fail instead of prompting for a password when root is needed
   -h, --help     Show help
   -v, --version  Show version
 `, version)
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+func handleConfig(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: usage: autonomix-cli config get|set|list|edit")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		handleConfigList()
+	case "get":
+		if len(args) < 2 {
+			fmt.Println("Error: usage: autonomix-cli config get <key>")
+			os.Exit(1)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		value, err := cfg.Settings.Get(args[1])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	case "set":
+		if len(args) < 2 {
+			fmt.Println("Error: usage: autonomix-cli config set <key> [value]")
+			os.Exit(1)
+		}
+		handleConfigSet(args[1], strings.Join(args[2:], " "))
+	case "edit":
+		handleConfigEdit()
+	default:
+		fmt.Printf("Error: unknown config command %q\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleConfigList() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\t")
+	for _, key := range config.SettingKeys() {
+		value, _ := cfg.Settings.Get(key)
+		if config.IsSecret(key) && value != "" {
+			value = "********"
+		}
+
+		note := ""
+		if !cfg.Settings.IsSet(key) {
+			note = "(default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, value, note)
+	}
+	w.Flush()
+}
+
+func handleConfigSet(key, value string) {
+	_, err := config.Update(func(cfg *config.Config) error {
+		return cfg.Settings.Set(key, value)
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if value == "" {
+		fmt.Printf("✓ Reset %s to its default\n", key)
+	} else {
+		fmt.Printf("✓ Set %s\n", key)
+	}
+}
+
+// handleConfigEdit opens a copy of the config in $VISUAL or $EDITOR and
+// saves it back only if it still parses.
+func handleConfigEdit() {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Make sure the file exists and is migrated before editing
+	if _, err := config.Update(func(*config.Config) error { return nil }); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmp, err := os.CreateTemp("", "autonomix-config-*.json")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(original)
+	tmp.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], tmp.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error: %s exited with %v\n", filepath.Base(fields[0]), err)
+		os.Exit(1)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if string(edited) == string(original) {
+		fmt.Println("No changes")
+		return
+	}
+
+	parsed, err := config.Parse(edited)
+	if err != nil {
+		fmt.Printf("Error: edited config is invalid, nothing saved: %v\n", err)
+		os.Exit(1)
+	}
+	if err := parsed.Settings.Validate(); err != nil {
+		fmt.Printf("Error: edited config is invalid, nothing saved: %v\n", err)
+		os.Exit(1)
+	}
+	for _, problem := range parsed.Problems() {
+		fmt.Printf("Warning: %v\n", problem)
+	}
+
+	if _, err := config.Update(func(cfg *config.Config) error {
+		*cfg = *parsed
+		return nil
+	}); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Saved %s\n", path)
+}
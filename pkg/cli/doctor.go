@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/pkg/doctor"
+)
+
+// handleDoctor prints a diagnosis of the environment and exits non-zero
+// when a check fails.
+func handleDoctor() {
+	checks := doctor.Run(loadSettings())
+	for _, c := range checks {
+		mark := "✓"
+		switch c.Status {
+		case doctor.Warn:
+			mark = "!"
+		case doctor.Fail:
+			mark = "✗"
+		}
+		fmt.Printf("%s %s: %s\n", mark, c.Name, c.Detail)
+	}
+	if doctor.Failed(checks) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/manager"
+)
+
+func addDryRun(url string, method binary.InstallMethod) {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.FindApp(manager.CleanRepoURL(url)) != nil {
+		fmt.Println("Error: repository already tracked")
+		os.Exit(1)
+	}
+
+	app, rel, err := manager.PlanAdd(url, cfg.Settings)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Would track %s as %s (Latest: %s)\n", app.RepoURL, app.Name, app.Latest)
+	if app.InstallStatus == config.StatusInstalled {
+		version := app.Version
+		if version == "" {
+			version = "found in PATH, version not probed"
+		}
+		fmt.Printf("  Already installed: %s\n", version)
+		return
+	}
+	printPlan(manager.PlanInstall(rel, *app, method, cfg.Settings))
+}
+
+func updateDryRun(app config.App, settings config.Settings) {
+	rel, err := manager.LatestRelease(app, settings)
+	if err != nil {
+		fmt.Printf("Error fetching release: %v\n", err)
+		os.Exit(1)
+	}
+
+	from := app.Version
+	if from == "" {
+		from = "not installed"
+	}
+	fmt.Printf("Would update %s from %s to %s\n", app.Name, from, rel.TagName)
+	printPlan(manager.PlanInstall(rel, app, binary.Auto, settings))
+}
+
+func removeDryRun(app config.App) {
+	fmt.Printf("Would remove %s\n", app.Name)
+	for _, step := range manager.PlanUninstall(app) {
+		fmt.Printf("  %s\n", step)
+	}
+	fmt.Println("  Stop tracking it")
+}
+
+// syncDryRun prints the install details of every step that installs.
+func syncDryRun(steps []manager.SyncStep, settings config.Settings) {
+	for _, step := range steps {
+		if step.Err != nil {
+			continue
+		}
+
+		switch step.Action {
+		case manager.SyncAdd, manager.SyncInstall, manager.SyncUpgrade, manager.SyncDowngrade:
+			fmt.Printf("\n%s %s:\n", syncVerbs[step.Action], syncName(step))
+			printPlan(manager.PlanInstall(step.Release, step.App, step.Method, settings))
+		case manager.SyncRemove:
+			fmt.Printf("\n%s %s:\n", syncVerbs[step.Action], syncName(step))
+			for _, s := range manager.PlanUninstall(step.App) {
+				fmt.Printf("  %s\n", s)
+			}
+		}
+	}
+}
+
+// printPlan shows what an install would do. A failed plan is reported but
+// does not abort the dry run.
+func printPlan(plan *installer.Plan, err error) {
+	if err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+		return
+	}
+
+	fmt.Printf("  Release:  %s\n", plan.Release)
+	fmt.Printf("  Method:   %s\n", plan.Method)
+	if plan.Asset != "" {
+		fmt.Printf("  Asset:    %s\n", plan.Asset)
+		fmt.Printf("  URL:      %s\n", plan.URL)
+	}
+	if len(plan.Command) > 0 {
+		fmt.Printf("  Command:  %s\n", strings.Join(plan.Command, " "))
+	}
+	if plan.Target != "" {
+		note := ""
+		if plan.Sudo {
+			note = " (requires sudo)"
+		}
+		fmt.Printf("  Target:   %s%s\n", plan.Target, note)
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+// handleExport writes the tracked apps as a manifest, to stdout or to the
+// file given with -o.
+func handleExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "", "Output file; .toml writes TOML (default stdout as YAML)")
+	format := fs.String("format", "", "yaml or toml (default from the output file)")
+	fs.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *format == "" {
+		*format = manifest.FormatFor(*output)
+	}
+	data, err := manager.Export(cfg).Encode(*format)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Exported %d app(s) to %s\n", len(cfg.Apps), *output)
+}
+
+// handleImport tracks every app listed in an exported file or manifest.
+func handleImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	install := fs.Bool("install", false, "Also install apps that are not installed yet")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Error: usage: autonomix-cli import [--install] <file>")
+		os.Exit(1)
+	}
+
+	m, err := manifest.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	settings := loadSettings()
+	failed := 0
+	for _, entry := range m.Apps {
+		app, err := manager.ImportApp(entry, *install, settings)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", entry.Repo, err)
+			failed++
+			continue
+		}
+
+		status := "tracked"
+		if app.Version != "" {
+			status = "installed " + app.Version
+		}
+		fmt.Printf("✓ %s (%s)\n", app.Name, status)
+	}
+
+	fmt.Printf("\n%d of %d app(s) imported\n", len(m.Apps)-failed, len(m.Apps))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tim/autonomix-cli/pkg/history"
+)
+
+// handleHistory prints the history, oldest first, optionally for one app.
+func handleHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	limit := fs.Int("n", 0, "Show only the last n entries")
+	fs.Parse(args)
+
+	entries, err := history.Load()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if fs.NArg() > 0 {
+		name := fs.Arg(0)
+		// Match on the repo so entries from before a rename are included
+		if cfg, err := loadConfig(); err == nil {
+			if app := cfg.FindAppByName(name); app != nil {
+				name = app.RepoURL
+			}
+		}
+		entries = history.For(entries, name)
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No history")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTION\tAPP\tVERSION\tMETHOD\tRESULT")
+	for _, e := range entries {
+		app := e.App
+		if app == "" {
+			app = e.Repo
+		}
+		method := e.Method
+		if method == "" {
+			method = "-"
+		}
+		if e.Sudo {
+			method += " (sudo)"
+		}
+		version := e.Versions()
+		if version == "" {
+			version = "-"
+		}
+		result := "✓"
+		if e.Result == history.ResultFailed {
+			result = "✗ " + e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04"), e.Action, app, version, method, result)
+	}
+	w.Flush()
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// handleInfo prints how autonomix identifies the running system, which
+// decides the package manager and the release assets it picks.
+func handleInfo() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Platform:\t%s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+	if rel, err := system.ReadOSRelease(); err != nil {
+		fmt.Fprintf(w, "OS:\tunknown (%v)\n", err)
+	} else {
+		fmt.Fprintf(w, "OS:\t%s\n", rel.PrettyName)
+		id := rel.ID
+		if len(rel.IDLike) > 0 {
+			id += " (like " + strings.Join(rel.IDLike, ", ") + ")"
+		}
+		fmt.Fprintf(w, "ID:\t%s\n", id)
+		if rel.VersionID != "" {
+			fmt.Fprintf(w, "Version:\t%s\n", rel.VersionID)
+		}
+	}
+
+	pm := system.DetectPackageManager()
+	if pm == "" {
+		fmt.Fprintf(w, "Package manager:\tnone found\n")
+	} else {
+		fmt.Fprintf(w, "Package manager:\t%s\n", pm)
+	}
+	fmt.Fprintf(w, "Package type:\t%s\n", packages.DisplayName(pm.Type()))
+	w.Flush()
+}
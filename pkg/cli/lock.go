@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+// handleLock re-resolves every manifest entry and rewrites the lockfile.
+func handleLock(args []string) {
+	flags := flag.NewFlagSet("lock", flag.ExitOnError)
+	file := flags.String("f", "", "Manifest file (default autonomix.yaml or autonomix.toml)")
+	flags.Parse(args)
+	settings := loadSettings()
+
+	path, m := loadManifest(*file)
+	lockPath := manifest.LockPath(path)
+	lock := readLock(lockPath)
+
+	fmt.Printf("Locking %d app(s) from %s...\n", len(m.Apps), path)
+	failed := lockApps(lock, m.Apps, settings)
+	for _, repo := range lock.Unlisted(m) {
+		lock.Remove(repo)
+	}
+	if err := lock.Save(lockPath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d app(s) could not be locked\n", failed)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Wrote %s\n", lockPath)
+}
+
+// handleInstall installs the apps of a manifest exactly as locked. Without
+// --frozen, entries missing from the lock or changed since are locked
+// first; with it, any difference between manifest and lock is an error.
+func handleInstall(args []string) {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	file := flags.String("f", "", "Manifest file (default autonomix.yaml or autonomix.toml)")
+	frozen := flags.Bool("frozen", false, "Fail instead of updating the lockfile")
+	flags.Parse(args)
+	settings := loadSettings()
+
+	path, m := loadManifest(*file)
+	lockPath := manifest.LockPath(path)
+
+	if *frozen {
+		lock, err := manifest.LoadLock(lockPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				fmt.Printf("Error: %s not found, run autonomix-cli lock first\n", lockPath)
+			} else {
+				fmt.Printf("Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		if problems := lockProblems(lock, m); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("✗ %s\n", problem)
+			}
+			fmt.Printf("\n%s does not match %s, run autonomix-cli lock\n", lockPath, path)
+			os.Exit(1)
+		}
+		installLocked(lock, m, settings)
+		return
+	}
+
+	lock := readLock(lockPath)
+	outdated := lock.Outdated(m, manifest.Platform())
+	unlisted := lock.Unlisted(m)
+	if len(outdated) > 0 || len(unlisted) > 0 {
+		fmt.Printf("Locking %d app(s)...\n", len(outdated))
+		failed := lockApps(lock, outdated, settings)
+		for _, repo := range unlisted {
+			lock.Remove(repo)
+		}
+		if err := lock.Save(lockPath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Updated %s\n", lockPath)
+		if failed > 0 {
+			fmt.Printf("\n%d app(s) could not be locked\n", failed)
+			os.Exit(1)
+		}
+	}
+	installLocked(lock, m, settings)
+}
+
+func installLocked(lock *manifest.Lock, m *manifest.Manifest, s config.Settings) {
+	failed := 0
+	for _, entry := range m.Apps {
+		locked := lock.Find(entry)
+		fmt.Printf("Installing %s %s...\n", entry.Repo, locked.Tag)
+		app, installed, err := manager.InstallLocked(entry, locked, s)
+		switch {
+		case err != nil:
+			fmt.Printf("✗ %s: %v\n", entry.Repo, err)
+			failed++
+		case installed:
+			fmt.Printf("✓ %s %s\n", app.Name, app.Version)
+		default:
+			fmt.Printf("- %s %s already installed\n", app.Name, app.Version)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d app(s) failed to install\n", failed)
+		os.Exit(1)
+	}
+}
+
+// lockApps resolves entries into lock and returns how many failed.
+func lockApps(lock *manifest.Lock, entries []manifest.App, s config.Settings) int {
+	failed := 0
+	for _, entry := range entries {
+		locked, err := manager.LockApp(entry, s)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", entry.Repo, err)
+			failed++
+			continue
+		}
+		lock.Put(*locked)
+		fmt.Printf("✓ %s %s (%s)\n", entry.Repo, locked.Tag, locked.Assets[0].Name)
+	}
+	return failed
+}
+
+// lockProblems explains every way lock differs from m on this platform.
+func lockProblems(lock *manifest.Lock, m *manifest.Manifest) []string {
+	var problems []string
+	for _, entry := range m.Apps {
+		locked := lock.Find(entry)
+		switch {
+		case locked == nil:
+			problems = append(problems, fmt.Sprintf("%s: not in the lockfile", entry.Repo))
+		case !locked.Matches(entry):
+			problems = append(problems, fmt.Sprintf("%s: changed since it was locked", entry.Repo))
+		case locked.Asset(manifest.Platform()) == nil:
+			problems = append(problems, fmt.Sprintf("%s: not locked for %s", entry.Repo, manifest.Platform()))
+		}
+	}
+	for _, repo := range lock.Unlisted(m) {
+		problems = append(problems, fmt.Sprintf("%s: locked but not in the manifest", repo))
+	}
+	return problems
+}
+
+// readLock loads the lockfile at path, starting an empty one when it does
+// not exist yet.
+func readLock(path string) *manifest.Lock {
+	lock, err := manifest.LoadLock(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &manifest.Lock{}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return lock
+}
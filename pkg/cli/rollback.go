@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/manager"
+)
+
+func handleRollback(args []string) {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	list := fs.Bool("list", false, "List versions available for rollback")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Error: app name required")
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := fs.Arg(0)
+	for _, app := range cfg.Apps {
+		if app.Name != name {
+			continue
+		}
+
+		if app.InstallMethod != config.InstallMethodBinary || app.BinaryPath == "" {
+			fmt.Printf("Error: rollback is only supported for binary installs\n")
+			os.Exit(1)
+		}
+		binName := filepath.Base(app.BinaryPath)
+
+		if *list {
+			versions, err := binary.ListVersions(binName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			active := binary.ActiveVersion(app.BinaryPath)
+			for _, v := range versions {
+				marker := " "
+				if v.Version == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\t%s\n", marker, v.Version, v.Installed.Format("2006-01-02 15:04"))
+			}
+			return
+		}
+
+		version, err := manager.RollbackApp(app, fs.Arg(1), cfg.Settings)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Rolled back %s to %s\n", name, version)
+		return
+	}
+
+	fmt.Printf("Error: %s not found\n", name)
+	os.Exit(1)
+}
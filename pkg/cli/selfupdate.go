@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/history"
+	"github.com/tim/autonomix-cli/pkg/selfupdate"
+)
+
+func handleSelfUpdate(args []string, version string) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	check := fs.Bool("check", false, "Only check for a newer release")
+	fs.Parse(args)
+
+	settings := loadSettings()
+	if *check {
+		rel, newer, err := selfupdate.Check(version, settings)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if newer {
+			fmt.Printf("autonomix-cli %s is available (current: %s)\n", rel.TagName, version)
+		} else {
+			fmt.Printf("autonomix-cli %s is up to date\n", version)
+		}
+		return
+	}
+
+	res, err := selfupdate.Update(version, os.Stdout, settings)
+	if errors.Is(err, selfupdate.ErrUpToDate) {
+		fmt.Printf("autonomix-cli %s is up to date\n", version)
+		return
+	}
+	if err != nil {
+		history.Append(history.Entry{Action: history.ActionUpdate, App: "autonomix-cli", Repo: selfupdate.RepoURL, From: version}, err)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	history.Append(history.Entry{
+		Action: history.ActionUpdate,
+		App:    "autonomix-cli",
+		Repo:   selfupdate.RepoURL,
+		From:   res.From,
+		To:     res.To,
+		Method: res.Method,
+	}, nil)
+
+	config.Update(func(cfg *config.Config) error {
+		if app := cfg.FindApp(selfupdate.RepoURL); app != nil {
+			app.Version = res.To
+			app.Latest = res.To
+			app.InstallMethod = res.Method
+		}
+		return nil
+	})
+
+	fmt.Printf("✓ Updated autonomix-cli %s -> %s (%s)\n", res.From, res.To, res.Method)
+	if res.Changelog != "" {
+		fmt.Printf("\nChangelog:\n%s\n", res.Changelog)
+	}
+}
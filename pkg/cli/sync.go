@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tim/autonomix-cli/pkg/manager"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+// defaultManifests are looked up in the working directory when -f is not given.
+var defaultManifests = []string{"autonomix.yaml", "autonomix.yml", "autonomix.toml"}
+
+var syncVerbs = map[manager.SyncAction]string{
+	manager.SyncAdd:       "Adding",
+	manager.SyncInstall:   "Installing",
+	manager.SyncUpgrade:   "Upgrading",
+	manager.SyncDowngrade: "Downgrading",
+	manager.SyncConfigure: "Configuring",
+	manager.SyncRemove:    "Removing",
+}
+
+func handleSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	file := fs.String("f", "", "Manifest file (default autonomix.yaml or autonomix.toml)")
+	prune := fs.Bool("prune", false, "Remove tracked apps missing from the manifest")
+	fs.Parse(args)
+
+	path, m := loadManifest(*file)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Resolving %d app(s) from %s...\n", len(m.Apps), path)
+	steps := manager.PlanSync(cfg, m, *prune, nil)
+	printSyncPlan(steps)
+
+	if dryRun {
+		syncDryRun(steps, cfg.Settings)
+		return
+	}
+
+	failed := 0
+	for _, step := range steps {
+		if step.Action == manager.SyncKeep {
+			continue
+		}
+		name := syncName(step)
+		if step.Err == nil {
+			fmt.Printf("%s %s...\n", syncVerbs[step.Action], name)
+		}
+		app, err := manager.ApplySync(step, cfg.Settings)
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		switch {
+		case step.Action == manager.SyncRemove:
+			fmt.Printf("✓ Removed %s\n", name)
+		case app != nil && app.Version != "":
+			fmt.Printf("✓ %s %s\n", app.Name, app.Version)
+		default:
+			fmt.Printf("✓ %s\n", name)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d app(s) failed to sync\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("✓ In sync")
+}
+
+// loadManifest reads the manifest at path, or the first default manifest
+// in the working directory when path is empty, exiting on failure.
+func loadManifest(path string) (string, *manifest.Manifest) {
+	if path == "" {
+		for _, name := range defaultManifests {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+		if path == "" {
+			fmt.Println("Error: no manifest found, pass one with -f")
+			os.Exit(1)
+		}
+	}
+
+	m, err := manifest.Load(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return path, m
+}
+
+func printSyncPlan(steps []manager.SyncStep) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tACTION\tFROM\tTO")
+	for _, step := range steps {
+		action := string(step.Action)
+		if step.Err != nil {
+			action = "error: " + step.Err.Error()
+		}
+		from, to := step.From, step.To
+		if from == "" {
+			from = "-"
+		}
+		if to == "" || step.Action == manager.SyncRemove {
+			to = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", syncName(step), action, from, to)
+	}
+	w.Flush()
+}
+
+func syncName(step manager.SyncStep) string {
+	if step.App.Name != "" {
+		return step.App.Name
+	}
+	return step.App.RepoURL
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/pkg/manager"
+)
+
+// handleVerify compares what the config records for installed apps with
+// what is on the system and, with --fix, updates the config to match.
+func handleVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "Update the config to match the system")
+	fs.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	apps := cfg.Apps
+	if fs.NArg() > 0 {
+		apps = nil
+		for _, name := range fs.Args() {
+			app := cfg.FindAppByName(name)
+			if app == nil {
+				fmt.Printf("Error: %s not found\n", name)
+				os.Exit(1)
+			}
+			apps = append(apps, *app)
+		}
+	}
+
+	drifted := 0
+	for _, app := range apps {
+		if !manager.Installed(app) {
+			fmt.Printf("- %s: not installed\n", app.Name)
+			continue
+		}
+
+		v := manager.VerifyApp(app)
+		if len(v.Drift) == 0 {
+			fmt.Printf("✓ %s %s\n", app.Name, app.Version)
+			continue
+		}
+		for _, d := range v.Drift {
+			fmt.Printf("✗ %s: %s\n", app.Name, d.Detail)
+		}
+		if !*fix {
+			drifted++
+			continue
+		}
+
+		updated, err := manager.FixDrift(v)
+		if err != nil {
+			fmt.Printf("  Error: %v\n", err)
+			drifted++
+			continue
+		}
+		if updated.Version == "" {
+			fmt.Printf("  Fixed: marked not installed, reinstall with: autonomix-cli update %s\n", app.Name)
+		} else {
+			fmt.Printf("  Fixed: recorded %s\n", updated.Version)
+		}
+	}
+
+	if drifted > 0 {
+		if !*fix {
+			fmt.Printf("\n%d app(s) drifted, run autonomix-cli verify --fix to update the config\n", drifted)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,123 @@
+// Package daemon installs the systemd user timer that runs the background
+// update check.
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// Unit names
+const (
+	ServiceName = "autonomix-check.service"
+	TimerName   = "autonomix-check.timer"
+)
+
+// UnitDir returns the systemd user unit directory.
+func UnitDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// Units returns the service and timer unit files for the autonomix-cli
+// binary at exe. The timer fires hourly; the check itself only queries apps
+// whose check-interval has passed, so frequent runs cost no API requests.
+func Units(exe string) (service, timer string) {
+	service = fmt.Sprintf(`[Unit]
+Description=Check GitHub releases tracked by autonomix-cli
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%q check --notify
+`, exe)
+
+	timer = fmt.Sprintf(`[Unit]
+Description=Periodic autonomix-cli update check
+
+[Timer]
+OnBootSec=5min
+OnCalendar=hourly
+RandomizedDelaySec=10min
+Persistent=true
+Unit=%s
+
+[Install]
+WantedBy=timers.target
+`, ServiceName)
+	return service, timer
+}
+
+// InstallTimer writes the units to UnitDir and enables the timer with
+// systemctl. The unit files are kept when systemctl fails, so the error
+// can be fixed and the timer enabled by hand.
+func InstallTimer() (string, error) {
+	dir, err := UnitDir()
+	if err != nil {
+		return "", err
+	}
+	if err := writeUnits(dir); err != nil {
+		return "", err
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return dir, err
+	}
+	return dir, systemctl("enable", "--now", TimerName)
+}
+
+// writeUnits writes the units to dir. They run the path autonomix-cli was
+// started as, not the file it resolves to: self-update replaces the
+// invoked symlink and pruning deletes old versions, which would leave the
+// timer running a stale or missing binary.
+func writeUnits(dir string) error {
+	exe, err := system.InvokedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	service, timer := Units(exe)
+	if err := os.WriteFile(filepath.Join(dir, ServiceName), []byte(service), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, TimerName), []byte(timer), 0644)
+}
+
+// UninstallTimer disables the timer and removes the unit files.
+func UninstallTimer() error {
+	dir, err := UnitDir()
+	if err != nil {
+		return err
+	}
+
+	// The timer may never have been enabled
+	systemctl("disable", "--now", TimerName)
+	for _, name := range []string{TimerName, ServiceName} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return systemctl("daemon-reload")
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %v: %s", args[0], err, out)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	service, timer := Units("/opt/my tools/autonomix-cli")
+
+	if !strings.Contains(service, `ExecStart="/opt/my tools/autonomix-cli" check --notify`) {
+		t.Errorf("service does not run the check:\n%s", service)
+	}
+	if !strings.Contains(timer, "Unit="+ServiceName) || !strings.Contains(timer, "WantedBy=timers.target") {
+		t.Errorf("timer does not start the service:\n%s", timer)
+	}
+}
+
+func TestWriteUnits_RunsInvokedPath(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "autonomix-cli")
+	if err := os.Symlink(exe, link); err != nil {
+		t.Fatal(err)
+	}
+	args := os.Args
+	os.Args = append([]string{link}, args[1:]...)
+	t.Cleanup(func() { os.Args = args })
+
+	dir := t.TempDir()
+	if err := writeUnits(dir); err != nil {
+		t.Fatal(err)
+	}
+	service, err := os.ReadFile(filepath.Join(dir, ServiceName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("ExecStart=%q check --notify", link); !strings.Contains(string(service), want) {
+		t.Errorf("service does not run the invoked path %s:\n%s", link, service)
+	}
+}
+
+func TestUnitDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if dir, _ := UnitDir(); dir != filepath.Join("/tmp/xdg", "systemd", "user") {
+		t.Errorf("UnitDir() = %s", dir)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/me")
+	if dir, _ := UnitDir(); dir != "/home/me/.config/systemd/user" {
+		t.Errorf("UnitDir() = %s", dir)
+	}
+}
@@ -0,0 +1,220 @@
+// Package doctor diagnoses the environment autonomix installs into, so a
+// failed install can be traced to its cause.
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// Status grades a check.
+type Status int
+
+const (
+	OK Status = iota
+	Warn
+	Fail
+)
+
+// Check is the outcome of one diagnosis.
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+}
+
+// tools are the package managers reported as available.
+var tools = []string{"apt-get", "dpkg", "dnf", "yum", "zypper", "rpm", "pacman", "flatpak", "snap", "brew"}
+
+// Run performs every check under settings s. It queries the GitHub API
+// once, for the rate limit, which does not count against it.
+func Run(s config.Settings) []Check {
+	checks := []Check{
+		Platform(),
+		PackageType(),
+		PackageManagers(),
+		Escalation(privilege.Escalator{Tool: s.EscalationTool}),
+	}
+	checks = append(checks, Paths(s.InstallDir)...)
+	checks = append(checks, GitHub(github.Client{Token: s.GitHubToken}), Config())
+	return append(checks, MissingBinaries()...)
+}
+
+// Failed reports whether any check failed.
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Platform reports the OS, architecture and distribution.
+func Platform() Check {
+	c := Check{Name: "Platform", Detail: runtime.GOOS + "/" + runtime.GOARCH}
+	if runtime.GOOS != "linux" {
+		return c
+	}
+	rel, err := system.ReadOSRelease()
+	if err != nil {
+		c.Status = Warn
+		c.Detail += ", os-release not readable: " + err.Error()
+		return c
+	}
+	c.Detail += ", " + rel.PrettyName
+	return c
+}
+
+// PackageType reports which package format installs use.
+func PackageType() Check {
+	pm := system.DetectPackageManager()
+	if pm == "" {
+		return Check{Name: "Package type", Status: Warn, Detail: "no supported package manager, only binaries and Homebrew can be installed"}
+	}
+	return Check{Name: "Package type", Detail: fmt.Sprintf("%s via %s", packages.DisplayName(pm.Type()), pm)}
+}
+
+// PackageManagers lists the package tools found in PATH.
+func PackageManagers() Check {
+	var found []string
+	for _, t := range tools {
+		if _, err := exec.LookPath(t); err == nil {
+			found = append(found, t)
+		}
+	}
+	if len(found) == 0 {
+		return Check{Name: "Package managers", Status: Warn, Detail: "none found"}
+	}
+	return Check{Name: "Package managers", Detail: strings.Join(found, ", ")}
+}
+
+// Escalation reports how commands needing root are run and whether that
+// works without a password prompt.
+func Escalation(esc privilege.Escalator) Check {
+	c := Check{Name: "Escalation"}
+	if privilege.IsRoot() {
+		c.Detail = "running as root"
+		return c
+	}
+	tool, err := esc.Resolve()
+	if err != nil {
+		c.Status = Warn
+		c.Detail = err.Error() + ", system paths and packages cannot be installed"
+		return c
+	}
+	c.Detail = tool
+	switch tool {
+	case privilege.Sudo, privilege.Doas, privilege.Run0:
+		argv := privilege.Wrap(tool, true, "true")
+		if exec.Command(argv[0], argv[1:]...).Run() == nil {
+			c.Detail += ", no password needed"
+		} else {
+			c.Detail += ", asks for a password (--non-interactive installs needing root will fail)"
+		}
+	default:
+		// Probing pkexec could pop up a polkit agent's password dialog
+		c.Detail += ", password prompt not checked (pkexec asks through a polkit agent)"
+	}
+	return c
+}
+
+// Paths reports whether each directory binaries are linked into, given the
+// install-dir setting, is in PATH.
+func Paths(installDir string) []Check {
+	var checks []Check
+	for _, dir := range binary.BinDirs(installDir) {
+		c := Check{Name: "PATH", Detail: dir + " is in PATH"}
+		if !binary.InPath(dir) {
+			c.Status = Warn
+			c.Detail = dir + " is not in PATH, binaries installed there will not be found"
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// GitHub reports whether the API is reachable and how much of the rate
+// limit is left.
+func GitHub(client github.Client) Check {
+	c := Check{Name: "GitHub API"}
+	rate, err := client.RateLimit()
+	if err != nil {
+		c.Status = Fail
+		c.Detail = "unreachable: " + err.Error()
+		return c
+	}
+
+	auth := "unauthenticated, set GITHUB_TOKEN or github-token for a higher limit"
+	if client.HasToken() {
+		auth = "authenticated"
+	}
+	c.Detail = fmt.Sprintf("reachable, %d/%d requests left, %s", rate.Remaining, rate.Limit, auth)
+	if rate.Remaining == 0 {
+		c.Status = Fail
+		c.Detail = fmt.Sprintf("rate limit used up until %s, %s", rate.Reset.Local().Format(time.Kitchen), auth)
+	}
+	return c
+}
+
+// Config reports whether the config file loads and its settings and app
+// entries are valid.
+func Config() Check {
+	c := Check{Name: "Config"}
+	path, _ := config.GetConfigPath()
+	cfg, err := config.Load()
+	if err != nil {
+		c.Status = Fail
+		c.Detail = err.Error()
+		return c
+	}
+
+	var problems []string
+	for _, p := range cfg.Problems() {
+		problems = append(problems, p.Error())
+	}
+	if err := cfg.Settings.Validate(); err != nil {
+		problems = append(problems, strings.Split(err.Error(), "\n")...)
+	}
+	if len(problems) > 0 {
+		c.Status = Fail
+		c.Detail = path + ": " + strings.Join(problems, "; ")
+		return c
+	}
+	c.Detail = fmt.Sprintf("%s, %d app(s) tracked", path, len(cfg.Apps))
+	return c
+}
+
+// MissingBinaries reports binary installs whose recorded path no longer
+// exists.
+func MissingBinaries() []Check {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	var checks []Check
+	for _, app := range cfg.Apps {
+		if app.InstallMethod != config.InstallMethodBinary || app.BinaryPath == "" {
+			continue
+		}
+		if _, err := os.Stat(app.BinaryPath); err != nil {
+			checks = append(checks, Check{
+				Name:   app.Name,
+				Status: Fail,
+				Detail: app.BinaryPath + " is missing, reinstall with: autonomix-cli update " + app.Name,
+			})
+		}
+	}
+	return checks
+}
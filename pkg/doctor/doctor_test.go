@@ -0,0 +1,63 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+func TestConfigAndMissingBinaries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.HomeEnv, home)
+
+	present := filepath.Join(home, "rg")
+	if err := os.WriteFile(present, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := config.Update(func(c *config.Config) error {
+		c.Apps = []config.App{
+			{Name: "rg", RepoURL: "https://github.com/BurntSushi/ripgrep", InstallMethod: config.InstallMethodBinary, BinaryPath: present},
+			{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", InstallMethod: config.InstallMethodBinary, BinaryPath: filepath.Join(home, "fzf")},
+			{Name: "bat", RepoURL: "https://github.com/sharkdp/bat", InstallMethod: config.InstallMethodPackage},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := Config(); c.Status != OK || !strings.Contains(c.Detail, "3 app(s)") {
+		t.Errorf("Config() = %+v", c)
+	}
+
+	missing := MissingBinaries()
+	if len(missing) != 1 || missing[0].Name != "fzf" || missing[0].Status != Fail {
+		t.Errorf("MissingBinaries() = %+v, want only fzf", missing)
+	}
+	if !Failed(missing) {
+		t.Error("Failed() = false with a missing binary")
+	}
+}
+
+func TestConfigInvalidSetting(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.HomeEnv, home)
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"settings": {"channel": "nightly"}, "apps": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Config()
+	if c.Status != Fail || !strings.Contains(c.Detail, "channel") {
+		t.Errorf("Config() = %+v, want a failure naming channel", c)
+	}
+}
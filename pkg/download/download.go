@@ -0,0 +1,274 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress describes how much of a download has been written. Total is -1
+// when the server does not report a length.
+type Progress struct {
+	Name       string
+	Downloaded int64
+	Total      int64
+}
+
+// Percent returns the completed fraction between 0 and 1, or 0 when the
+// total size is unknown.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Downloaded) / float64(p.Total)
+}
+
+// Done reports whether the download has finished.
+func (p Progress) Done() bool {
+	return p.Total >= 0 && p.Downloaded >= p.Total
+}
+
+// ProgressFunc receives progress updates while a download runs.
+type ProgressFunc func(Progress)
+
+// Downloader fetches URLs to disk. Data is streamed to "<dest>.part" and
+// renamed into place once complete, so an interrupted download is resumed
+// with an HTTP Range request on the next attempt.
+type Downloader struct {
+	Client     *http.Client
+	Retries    int
+	Backoff    time.Duration
+	OnProgress ProgressFunc
+
+	// Interval throttles progress callbacks; the final update is always sent.
+	Interval time.Duration
+
+	// IdleTimeout aborts an attempt that receives no data for this long,
+	// so a stalled connection is retried instead of hanging; zero waits
+	// forever.
+	IdleTimeout time.Duration
+}
+
+// New returns a Downloader with sensible timeouts and retry settings.
+func New() *Downloader {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}
+
+	return &Downloader{
+		Client:      &http.Client{Transport: transport},
+		Retries:     3,
+		Backoff:     time.Second,
+		Interval:    100 * time.Millisecond,
+		IdleTimeout: 30 * time.Second,
+	}
+}
+
+// statusError is returned for HTTP responses that retrying will not fix.
+type statusError struct {
+	status string
+}
+
+func (e *statusError) Error() string { return "bad status: " + e.status }
+
+// errStalled cancels an attempt that stopped receiving data.
+var errStalled = errors.New("download stalled")
+
+// Fetch downloads url to dest, retrying transient failures with exponential
+// backoff and resuming from any partial file left by an earlier attempt.
+func (d *Downloader) Fetch(ctx context.Context, url, dest string) error {
+	var err error
+	for attempt := 0; attempt <= d.Retries; attempt++ {
+		if attempt > 0 {
+			wait := d.Backoff << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		err = d.fetchOnce(ctx, url, dest)
+		if err == nil {
+			return nil
+		}
+
+		var se *statusError
+		if errors.As(err, &se) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", d.Retries+1, err)
+}
+
+func (d *Downloader) fetchOnce(ctx context.Context, url, dest string) error {
+	part := dest + ".part"
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var stall *time.Timer
+	if d.IdleTimeout > 0 {
+		stall = time.AfterFunc(d.IdleTimeout, func() { cancel(errStalled) })
+		defer stall.Stop()
+	}
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return stalled(ctx, d.IdleTimeout, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if start, ok := rangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			// The body does not continue the partial file; restart cleanly
+			os.Remove(part)
+			return fmt.Errorf("server resumed at the wrong offset (%q for byte %d), restarting download",
+				resp.Header.Get("Content-Range"), offset)
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// Server ignored the range, start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is stale or already complete; restart cleanly
+		os.Remove(part)
+		return fmt.Errorf("range not satisfiable, restarting download")
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return &statusError{status: resp.Status}
+	}
+
+	out, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	pw := &progressWriter{
+		name:       nameFromURL(url),
+		downloaded: offset,
+		total:      total,
+		interval:   d.Interval,
+		report:     d.OnProgress,
+	}
+
+	var w io.Writer = io.MultiWriter(out, pw)
+	if stall != nil {
+		w = io.MultiWriter(w, resetWriter{stall, d.IdleTimeout})
+	}
+	_, err = io.Copy(w, resp.Body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return stalled(ctx, d.IdleTimeout, err)
+	}
+	if total >= 0 && pw.downloaded != total {
+		return fmt.Errorf("short download: got %d of %d bytes", pw.downloaded, total)
+	}
+
+	// The final update always carries a known total so callers see completion
+	pw.total = pw.downloaded
+	pw.flush()
+	return os.Rename(part, dest)
+}
+
+// stalled reports err as a stall when the idle timeout cancelled the attempt.
+func stalled(ctx context.Context, idle time.Duration, err error) error {
+	if context.Cause(ctx) == errStalled {
+		return fmt.Errorf("%w: no data received for %s", errStalled, idle)
+	}
+	return err
+}
+
+// rangeStart returns the first byte position of a Content-Range header
+// such as "bytes 100-199/200".
+func rangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	return n, err == nil
+}
+
+// resetWriter pushes back the idle timeout whenever data arrives.
+type resetWriter struct {
+	timer *time.Timer
+	idle  time.Duration
+}
+
+func (w resetWriter) Write(b []byte) (int, error) {
+	w.timer.Reset(w.idle)
+	return len(b), nil
+}
+
+type progressWriter struct {
+	name       string
+	downloaded int64
+	total      int64
+	interval   time.Duration
+	last       time.Time
+	report     ProgressFunc
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.downloaded += int64(len(b))
+	if w.report != nil && time.Since(w.last) >= w.interval {
+		w.flush()
+	}
+	return len(b), nil
+}
+
+func (w *progressWriter) flush() {
+	if w.report == nil {
+		return
+	}
+	w.last = time.Now()
+	w.report(Progress{Name: w.name, Downloaded: w.downloaded, Total: w.total})
+}
+
+func nameFromURL(url string) string {
+	if idx := strings.LastIndex(url, "/"); idx != -1 {
+		return url[idx+1:]
+	}
+	return url
+}
@@ -0,0 +1,162 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetch_ResumesPartialFile(t *testing.T) {
+	content := []byte(strings.Repeat("autonomix", 1000))
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(dest+".part", content[:4000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var last Progress
+	d := New()
+	d.OnProgress = func(p Progress) { last = p }
+	if err := d.Fetch(context.Background(), srv.URL+"/asset", dest); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded content does not match")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("expected a single ranged request, got %v", ranges)
+	}
+	if !last.Done() || last.Downloaded != int64(len(content)) {
+		t.Errorf("final progress = %+v, want complete", last)
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Errorf("partial file should be removed after completion")
+	}
+}
+
+func TestFetch_RetriesServerErrors(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	d := New()
+	d.Backoff = time.Millisecond
+	dest := filepath.Join(t.TempDir(), "asset")
+	if err := d.Fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestFetch_DoesNotRetryNotFound(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	d := New()
+	d.Backoff = time.Millisecond
+	if err := d.Fetch(context.Background(), srv.URL, filepath.Join(t.TempDir(), "asset")); err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestFetch_RetriesStalledDownload(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Content-Length", "2")
+			w.Write([]byte("o"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	d := New()
+	d.Backoff = time.Millisecond
+	d.IdleTimeout = 50 * time.Millisecond
+	dest := filepath.Join(t.TempDir(), "asset")
+	if err := d.Fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "ok" {
+		t.Errorf("downloaded %q, want %q", got, "ok")
+	}
+}
+
+func TestFetch_RestartsOnMismatchedContentRange(t *testing.T) {
+	content := []byte(strings.Repeat("autonomix", 1000))
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.Header.Get("Range") != "" {
+			// Resume from the wrong offset
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content)
+			return
+		}
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(dest+".part", content[:4000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New()
+	d.Backoff = time.Millisecond
+	if err := d.Fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded content does not match")
+	}
+	if len(ranges) != 2 || ranges[1] != "" {
+		t.Errorf("expected a restart without Range, got %v", ranges)
+	}
+}
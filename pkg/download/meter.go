@@ -0,0 +1,46 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const meterWidth = 30
+
+// LineMeter returns a ProgressFunc that redraws a single-line progress bar
+// on w, suitable for interactive terminals.
+func LineMeter(w io.Writer) ProgressFunc {
+	return func(p Progress) {
+		if p.Total <= 0 {
+			fmt.Fprintf(w, "\r  %s", FormatBytes(p.Downloaded))
+			return
+		}
+
+		filled := int(p.Percent() * meterWidth)
+		if filled > meterWidth {
+			filled = meterWidth
+		}
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", meterWidth-filled)
+		fmt.Fprintf(w, "\r  [%s] %3.0f%%  %s / %s", bar, p.Percent()*100,
+			FormatBytes(p.Downloaded), FormatBytes(p.Total))
+
+		if p.Done() {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// FormatBytes renders a byte count using binary units.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -2,8 +2,12 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,6 +16,7 @@ type Asset struct {
 	Name               string `json:"name"`
 	BrowserDownloadURL string `json:"browser_download_url"`
 	Size               int    `json:"size"`
+	Digest             string `json:"digest"` // "sha256:<hex>", empty for older releases
 }
 
 type Release struct {
@@ -20,37 +25,219 @@ type Release struct {
 	Assets  []Asset `json:"assets"`
 	Body    string  `json:"body"`
 	HTMLURL string  `json:"html_url"`
+
+	Prerelease bool `json:"prerelease"`
+	Draft      bool `json:"draft"`
+}
+
+// Client makes GitHub API requests. Token authenticates them when
+// GITHUB_TOKEN is not set, normally from the github-token setting; the
+// zero Client relies on GITHUB_TOKEN alone.
+type Client struct {
+	Token string
 }
 
 // GetLatestRelease fetches the latest release info for a github repo url
 // url format: https://github.com/owner/repo
-func GetLatestRelease(repoURL string) (*Release, error) {
+func (c Client) GetLatestRelease(repoURL string) (*Release, error) {
+	repoPath, err := repoPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var rel Release
+	if err := c.get(fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repoPath), &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// GetChannelRelease fetches the newest release on a channel. The stable
+// channel is GitHub's latest release; with prerelease, releases marked as
+// prereleases are considered too.
+func (c Client) GetChannelRelease(repoURL string, prerelease bool) (*Release, error) {
+	if !prerelease {
+		return c.GetLatestRelease(repoURL)
+	}
+
+	repoPath, err := repoPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	if err := c.get(fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=20", repoPath), &releases); err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		if !releases[i].Draft {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no releases found")
+}
+
+// GetReleaseByTag fetches the release tagged tag. A missing or extra
+// leading "v" is tolerated, so pins can be written either way.
+func (c Client) GetReleaseByTag(repoURL, tag string) (*Release, error) {
+	repoPath, err := repoPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	alt := "v" + tag
+	if strings.HasPrefix(tag, "v") {
+		alt = strings.TrimPrefix(tag, "v")
+	}
+
+	// Only a missing tag is worth retrying; rate limits and network
+	// errors are returned as they are
+	var rel Release
+	err = c.get(fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repoPath, url.PathEscape(tag)), &rel)
+	if isNotFound(err) {
+		err = c.get(fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repoPath, url.PathEscape(alt)), &rel)
+		if isNotFound(err) {
+			return nil, fmt.Errorf("release %s not found: %w", tag, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// ResolveRelease returns the release an app should be on: the pinned tag
+// when pin is set, otherwise the newest release on its channel.
+func (c Client) ResolveRelease(repoURL, pin string, prerelease bool) (*Release, error) {
+	if pin != "" {
+		return c.GetReleaseByTag(repoURL, pin)
+	}
+	return c.GetChannelRelease(repoURL, prerelease)
+}
+
+func repoPath(repoURL string) (string, error) {
 	parts := strings.Split(repoURL, "github.com/")
 	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid github url")
+		return "", fmt.Errorf("invalid github url")
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(parts[1], "/"), ".git"), nil
+}
+
+// get decodes the JSON response of a GitHub API request into v.
+func (c Client) get(apiURL string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
 	}
-	repoPath := strings.TrimSuffix(parts[1], "/")
-	
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repoPath)
-	
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := c.token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	client := http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(apiURL)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
+	if err := rateLimited(resp); err != nil {
+		return err
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("github api returned status: %d", resp.StatusCode)
+		return &StatusError{StatusCode: resp.StatusCode}
 	}
 
-	var rel Release
-	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// StatusError reports a GitHub API response other than 200 OK that is not
+// a rate limit.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("github api returned status: %d", e.StatusCode)
+}
+
+// isNotFound reports whether err is a 404 response.
+func isNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+// RateLimitError reports that GitHub refused a request because the API
+// rate limit is used up.
+type RateLimitError struct {
+	Reset time.Time // When requests are allowed again; zero if unknown
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return "github api rate limit exceeded"
+	}
+	return fmt.Sprintf("github api rate limit exceeded until %s", e.Reset.Local().Format("15:04"))
+}
+
+// rateLimited returns a RateLimitError for primary and secondary rate
+// limit responses.
+func rateLimited(resp *http.Response) error {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &RateLimitError{Reset: time.Now().Add(time.Duration(secs) * time.Second)}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	e := &RateLimitError{}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		e.Reset = time.Unix(reset, 0)
+	}
+	return e
+}
+
+// Rate is the state of the core API rate limit.
+type Rate struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimit fetches the core API rate limit for the configured token.
+// Checking it does not count against the limit.
+func (c Client) RateLimit() (*Rate, error) {
+	var body struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := c.get("https://api.github.com/rate_limit", &body); err != nil {
 		return nil, err
 	}
+	core := body.Resources.Core
+	return &Rate{Limit: core.Limit, Remaining: core.Remaining, Reset: time.Unix(core.Reset, 0)}, nil
+}
 
-	return &rel, nil
+// HasToken reports whether API requests are authenticated.
+func (c Client) HasToken() bool {
+	return c.token() != ""
+}
+
+func (c Client) token() string {
+	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
+		return t
+	}
+	return c.Token
 }
@@ -0,0 +1,145 @@
+// Package history keeps an append-only log of everything autonomix does to
+// an app, one JSON object per line in the state directory.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+// Action is what was done to an app.
+type Action string
+
+const (
+	ActionAdd      Action = "add"
+	ActionInstall  Action = "install"
+	ActionUpdate   Action = "update"
+	ActionRollback Action = "rollback"
+	ActionRemove   Action = "remove"
+	ActionFix      Action = "fix" // the config was corrected to match the system
+)
+
+// Results
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+// Entry is one line of the history.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	App    string    `json:"app"`
+	Repo   string    `json:"repo"`
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+	Asset  string    `json:"asset,omitempty"`
+	Digest string    `json:"digest,omitempty"`
+	Method string    `json:"method,omitempty"`
+	Sudo   bool      `json:"sudo,omitempty"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Path returns the history file.
+func Path() (string, error) {
+	dir, err := config.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// Append adds e to the history, filling in the time and, from err, the
+// result. Each entry is written with a single append so concurrent
+// processes never interleave lines.
+func Append(e Entry, err error) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	e.Result = ResultOK
+	if err != nil {
+		e.Result = ResultFailed
+		e.Error = err.Error()
+	}
+
+	path, pathErr := Path()
+	if pathErr != nil {
+		return pathErr
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	line, jsonErr := json.Marshal(e)
+	if jsonErr != nil {
+		return jsonErr
+	}
+	f, openErr := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if openErr != nil {
+		return openErr
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load returns the history, oldest first. Lines that do not parse are
+// skipped so one damaged line cannot hide the rest.
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// For returns the entries for the app with the given name or repo URL.
+func For(entries []Entry, app string) []Entry {
+	var matched []Entry
+	for _, e := range entries {
+		if strings.EqualFold(e.App, app) || strings.EqualFold(e.Repo, app) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// Versions describes the version change, such as "1.2.0 -> 1.3.0".
+func (e Entry) Versions() string {
+	switch {
+	case e.From != "" && e.To != "":
+		return e.From + " -> " + e.To
+	case e.To != "":
+		return e.To
+	}
+	return e.From
+}
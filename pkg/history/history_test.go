@@ -0,0 +1,56 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+func TestAppendLoad(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	if entries, err := Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty history, got %v, %v", entries, err)
+	}
+
+	rg := "https://github.com/BurntSushi/ripgrep"
+	if err := Append(Entry{Action: ActionInstall, App: "rg", Repo: rg, To: "14.1.0", Sudo: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A damaged line must not hide later entries
+	path, _ := Path()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+
+	Append(Entry{Action: ActionUpdate, App: "rg", Repo: rg, From: "14.1.0", To: "14.1.1"}, errors.New("checksum mismatch"))
+	Append(Entry{Action: ActionAdd, App: "fzf", Repo: "https://github.com/junegunn/fzf"}, nil)
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Result != ResultOK || entries[0].Time.IsZero() || !entries[0].Sudo {
+		t.Errorf("unexpected first entry %+v", entries[0])
+	}
+	if entries[1].Result != ResultFailed || entries[1].Error != "checksum mismatch" {
+		t.Errorf("failure not recorded: %+v", entries[1])
+	}
+
+	got := For(entries, "RG")
+	if len(got) != 2 || len(For(entries, rg)) != 2 {
+		t.Errorf("expected two entries for rg, got %d", len(got))
+	}
+	if got[1].Versions() != "14.1.0 -> 14.1.1" {
+		t.Errorf("versions = %q", got[1].Versions())
+	}
+}
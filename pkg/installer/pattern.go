@@ -0,0 +1,187 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+)
+
+// Asset patterns select a release asset by name. A pattern is either a glob
+// ("tool_{version}_{os}_{arch}.tar.gz") or, when prefixed with "re:", a
+// regular expression. The placeholders {version}, {os} and {arch} are
+// expanded for the current release and platform before matching.
+
+const regexPatternPrefix = "re:"
+
+var (
+	osAliases = map[string][]string{
+		"linux":  {"linux"},
+		"darwin": {"darwin", "macos", "osx", "apple"},
+	}
+	archAliases = map[string][]string{
+		"amd64": {"amd64", "x86_64", "x64"},
+		"arm64": {"arm64", "aarch64", "armv8"},
+	}
+)
+
+// CompilePattern turns an asset pattern into a regexp for the given release version.
+func CompilePattern(pattern, version string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, regexPatternPrefix) {
+		expr := expandPlaceholders(strings.TrimPrefix(pattern, regexPatternPrefix), version)
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		return re, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end == -1 {
+				b.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			placeholder := pattern[i : i+end+1]
+			expanded := expandPlaceholders(placeholder, version)
+			if expanded == placeholder {
+				b.WriteString(regexp.QuoteMeta(placeholder))
+			} else {
+				b.WriteString(expanded)
+			}
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	re, err := regexp.Compile("(?i)^" + b.String() + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+func expandPlaceholders(expr, version string) string {
+	v := strings.TrimPrefix(version, "v")
+	expr = strings.ReplaceAll(expr, "{version}", "v?"+regexp.QuoteMeta(v))
+	expr = strings.ReplaceAll(expr, "{os}", aliasGroup(osAliases, runtime.GOOS))
+	expr = strings.ReplaceAll(expr, "{arch}", aliasGroup(archAliases, runtime.GOARCH))
+	return expr
+}
+
+func aliasGroup(aliases map[string][]string, key string) string {
+	names, ok := aliases[key]
+	if !ok {
+		names = []string{key}
+	}
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = regexp.QuoteMeta(n)
+	}
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
+
+// MatchAssets returns the assets whose name matches the pattern.
+func MatchAssets(assets []github.Asset, pattern, version string) ([]github.Asset, error) {
+	re, err := CompilePattern(pattern, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []github.Asset
+	for _, asset := range assets {
+		if re.MatchString(asset.Name) {
+			matched = append(matched, asset)
+		}
+	}
+	return matched, nil
+}
+
+// SelectAsset picks the asset to install from candidates. When pattern is set,
+// the first candidate matching it wins and a miss is an error; otherwise the
+// first candidate is used.
+func SelectAsset(candidates []github.Asset, pattern, version string) (*github.Asset, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no compatible assets found")
+	}
+	if pattern == "" {
+		return &candidates[0], nil
+	}
+
+	matched, err := MatchAssets(candidates, pattern, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no asset matches pattern %q", pattern)
+	}
+	return &matched[0], nil
+}
+
+// SelectBinary picks from binary assets the same way SelectAsset does,
+// trying them from the highest priority down.
+func SelectBinary(binaries []binary.BinaryAsset, pattern, version string) (*binary.BinaryAsset, error) {
+	sorted := slices.Clone(binaries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	assets := make([]github.Asset, len(sorted))
+	for i, b := range sorted {
+		assets[i] = b.Asset
+	}
+
+	asset, err := SelectAsset(assets, pattern, version)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sorted {
+		if sorted[i].Asset.Name == asset.Name {
+			return &sorted[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no binary asset named %s", asset.Name)
+}
+
+// PatternFromAsset derives a reusable glob pattern from an asset chosen for
+// a release, replacing the version, OS and architecture with placeholders.
+func PatternFromAsset(assetName, version string) string {
+	pattern := assetName
+
+	for _, v := range []string{version, strings.TrimPrefix(version, "v")} {
+		if v == "" {
+			continue
+		}
+		if idx := strings.Index(pattern, v); idx != -1 {
+			pattern = pattern[:idx] + "{version}" + pattern[idx+len(v):]
+			break
+		}
+	}
+
+	pattern = replaceAlias(pattern, osAliases[runtime.GOOS], "{os}")
+	pattern = replaceAlias(pattern, archAliases[runtime.GOARCH], "{arch}")
+	return pattern
+}
+
+func replaceAlias(name string, aliases []string, placeholder string) string {
+	lower := strings.ToLower(name)
+	for _, alias := range aliases {
+		if idx := strings.Index(lower, alias); idx != -1 {
+			return name[:idx] + placeholder + name[idx+len(alias):]
+		}
+	}
+	return name
+}
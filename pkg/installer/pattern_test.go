@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+)
+
+func TestSelectAsset_Pattern(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("fixture asset names target linux/amd64")
+	}
+
+	assets := []github.Asset{
+		{Name: "tool_1.2.0_linux_amd64.deb"},
+		{Name: "tool-1.2.0-x86_64-unknown-linux-musl.tar.gz"},
+		{Name: "tool-1.2.0-x86_64-unknown-linux-gnu.tar.gz"},
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", "tool_1.2.0_linux_amd64.deb"},
+		{"tool-{version}-{arch}-unknown-{os}-gnu.tar.gz", "tool-1.2.0-x86_64-unknown-linux-gnu.tar.gz"},
+		{"*musl*", "tool-1.2.0-x86_64-unknown-linux-musl.tar.gz"},
+		{"re:gnu\\.tar\\.gz$", "tool-1.2.0-x86_64-unknown-linux-gnu.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		got, err := SelectAsset(assets, tt.pattern, "v1.2.0")
+		if err != nil {
+			t.Fatalf("SelectAsset(%q) returned error: %v", tt.pattern, err)
+		}
+		if got.Name != tt.want {
+			t.Errorf("SelectAsset(%q) = %s, want %s", tt.pattern, got.Name, tt.want)
+		}
+	}
+
+	if _, err := SelectAsset(assets, "*.rpm", "v1.2.0"); err == nil {
+		t.Errorf("expected error for pattern with no match")
+	}
+}
+
+func TestSelectBinary(t *testing.T) {
+	binaries := []binary.BinaryAsset{
+		{Asset: github.Asset{Name: "tool-linux-musl.tar.gz"}, Priority: 1},
+		{Asset: github.Asset{Name: "tool-linux-gnu.tar.gz"}, Priority: 2},
+		{Asset: github.Asset{Name: "tool-linux"}, Priority: 0},
+	}
+
+	got, err := SelectBinary(binaries, "", "v1.0.0")
+	if err != nil || got.Asset.Name != "tool-linux-gnu.tar.gz" {
+		t.Errorf("SelectBinary without pattern = %v, %v, want the highest priority asset", got, err)
+	}
+	got, err = SelectBinary(binaries, "*musl*", "v1.0.0")
+	if err != nil || got.Asset.Name != "tool-linux-musl.tar.gz" {
+		t.Errorf("SelectBinary(*musl*) = %v, %v", got, err)
+	}
+	if _, err := SelectBinary(binaries, "*.zip", "v1.0.0"); err == nil {
+		t.Error("expected error for pattern with no match")
+	}
+}
+
+func TestPatternFromAsset_RoundTrip(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("fixture asset names target linux/amd64")
+	}
+
+	pattern := PatternFromAsset("tool-v1.2.0-x86_64-unknown-linux-gnu.tar.gz", "v1.2.0")
+	if want := "tool-{version}-{arch}-unknown-{os}-gnu.tar.gz"; pattern != want {
+		t.Fatalf("PatternFromAsset = %s, want %s", pattern, want)
+	}
+
+	re, err := CompilePattern(pattern, "v1.3.0")
+	if err != nil {
+		t.Fatalf("CompilePattern returned error: %v", err)
+	}
+	if !re.MatchString("tool-v1.3.0-x86_64-unknown-linux-gnu.tar.gz") {
+		t.Errorf("pattern %s should match the next release", pattern)
+	}
+	if re.MatchString("tool-v1.3.0-x86_64-unknown-linux-musl.tar.gz") {
+		t.Errorf("pattern %s should not match a different variant", pattern)
+	}
+}
@@ -0,0 +1,523 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/cache"
+	"github.com/tim/autonomix-cli/pkg/download"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/homebrew"
+	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+)
+
+// Stage names a step of the install pipeline.
+type Stage string
+
+const (
+	StageResolve  Stage = "resolve"
+	StageSelect   Stage = "select"
+	StageDownload Stage = "download"
+	StageVerify   Stage = "verify"
+	StageExtract  Stage = "extract"
+	StageInstall  Stage = "install"
+	StageRecord   Stage = "record"
+)
+
+// Request describes what to install. Release and Asset are optional: the
+// release is resolved from the app's repository and the asset is selected
+// automatically when they are not given. Digest, when set, is the SHA-256
+// the downloaded asset must have. Settings are the global settings the
+// install follows; unset fields take their defaults.
+type Request struct {
+	App      config.App
+	Method   binary.InstallMethod
+	Release  *github.Release
+	Asset    *github.Asset
+	Digest   string
+	Settings config.Settings
+}
+
+// Result describes a successful install.
+type Result struct {
+	Method       string // One of the config.InstallMethod* constants
+	Version      string
+	Path         string
+	Asset        string
+	Digest       string
+	BinaryDigest string // SHA-256 of the installed binary, for binary installs
+	AssetPattern string // Set when the asset was chosen explicitly
+	Sudo         bool   // The install ran with sudo
+	Message      string
+}
+
+// Apply records the install on app. It is the only place install outcomes
+// are written to the config, so the CLI and TUI stay consistent.
+func (r *Result) Apply(app *config.App) {
+	app.Version = r.Version
+	app.InstallMethod = r.Method
+	app.BinaryPath = r.Path
+	app.BinaryDigest = r.BinaryDigest
+	app.AssetDigest = r.Digest
+	app.Adopted = false
+	app.InstallStatus = config.StatusInstalled
+	app.InstallError = ""
+	if r.AssetPattern != "" {
+		app.AssetPattern = r.AssetPattern
+	}
+}
+
+// StageError reports the pipeline stage an install failed in.
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error { return e.Err }
+
+// ApplyError records a failed install on app.
+func ApplyError(app *config.App, err error) {
+	app.InstallStatus = config.StatusFailed
+	app.InstallError = err.Error()
+}
+
+// Pipeline runs a Request through the install stages. Prepare and Install
+// can be called separately so the TUI can hand the terminal over for the
+// install stage, which may prompt for a sudo password.
+type Pipeline struct {
+	Request Request
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// OnProgress receives download progress; nil disables reporting.
+	OnProgress download.ProgressFunc
+
+	release    *github.Release
+	method     string
+	asset      *github.Asset
+	binaryName string
+	target     binary.InstallMethod
+	formula    string
+	assetPath  string
+	binaryPath string
+	digest     string
+	sudo       bool
+
+	cache  *cache.Cache
+	cached bool // assetPath lives in the download cache
+}
+
+// New returns a pipeline for req wired to the process's standard streams.
+func New(req Request) *Pipeline {
+	return &Pipeline{
+		Request:    req,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		OnProgress: download.LineMeter(os.Stdout),
+	}
+}
+
+// settings returns the request's settings with defaults applied.
+func (p *Pipeline) settings() config.Settings {
+	return p.Request.Settings.WithDefaults()
+}
+
+// Run executes every stage and cleans up temporary files.
+func (p *Pipeline) Run() (*Result, error) {
+	defer p.Cleanup()
+
+	if err := p.Prepare(); err != nil {
+		return nil, err
+	}
+	return p.Install()
+}
+
+// Prepare runs the non-interactive stages: resolve, select, download,
+// verify and extract.
+func (p *Pipeline) Prepare() error {
+	if err := p.resolve(); err != nil {
+		return &StageError{StageResolve, err}
+	}
+	if err := p.selectAsset(); err != nil {
+		return &StageError{StageSelect, err}
+	}
+	if p.method == config.InstallMethodHomebrew {
+		return nil
+	}
+	if err := p.download(); err != nil {
+		return &StageError{StageDownload, err}
+	}
+	if err := p.verify(); err != nil {
+		return &StageError{StageVerify, err}
+	}
+	if err := p.extract(); err != nil {
+		return &StageError{StageExtract, err}
+	}
+	return nil
+}
+
+// Plan describes what a pipeline would do, for dry runs.
+type Plan struct {
+	Release string
+	Method  string // One of the config.InstallMethod* constants
+	Asset   string
+	URL     string
+	Command []string // Package manager or Homebrew command
+	Target  string   // Binary install path
+	Sudo    bool
+}
+
+// Plan runs only the resolve and select stages and reports what the
+// remaining stages would do. Nothing is downloaded, installed or recorded.
+func (p *Pipeline) Plan() (*Plan, error) {
+	if err := p.resolve(); err != nil {
+		return nil, &StageError{StageResolve, err}
+	}
+	if err := p.selectAsset(); err != nil {
+		return nil, &StageError{StageSelect, err}
+	}
+
+	plan := &Plan{Release: p.release.TagName, Method: p.method}
+	if p.asset != nil {
+		plan.Asset = p.asset.Name
+		plan.URL = p.asset.BrowserDownloadURL
+	}
+
+	switch p.method {
+	case config.InstallMethodPackage:
+		cmd, err := GetInstallCmd(p.downloadPath(), p.binaryOptions().Escalator)
+		if err != nil {
+			return nil, &StageError{StageInstall, err}
+		}
+		plan.Command = cmd.Args
+		plan.Sudo = !privilege.IsRoot()
+	case config.InstallMethodHomebrew:
+		plan.Command = []string{"brew", "install", p.formula}
+	case config.InstallMethodBinary:
+		plan.Target, plan.Sudo = binary.TargetPath(p.binaryName, p.target, p.settings().InstallDir)
+	}
+	return plan, nil
+}
+
+// Install runs the install and record stages. Prepare must have succeeded.
+func (p *Pipeline) Install() (*Result, error) {
+	path, err := p.install()
+	if err != nil {
+		return nil, &StageError{StageInstall, err}
+	}
+	return p.record(path), nil
+}
+
+// Cleanup removes files downloaded or extracted by the pipeline.
+func (p *Pipeline) Cleanup() {
+	if p.assetPath != "" && !p.cached {
+		os.Remove(p.assetPath)
+	}
+	if p.binaryPath != "" && p.binaryPath != p.assetPath {
+		os.Remove(p.binaryPath)
+	}
+}
+
+// Method reports how the selected asset will be installed.
+func (p *Pipeline) Method() string { return p.method }
+
+// Asset returns the selected asset, or nil for Homebrew installs.
+func (p *Pipeline) Asset() *github.Asset { return p.asset }
+
+// Release returns the resolved release.
+func (p *Pipeline) Release() *github.Release { return p.release }
+
+// Digest returns the SHA-256 of the downloaded asset once verified.
+func (p *Pipeline) Digest() string { return p.digest }
+
+func (p *Pipeline) resolve() error {
+	if p.Request.Release != nil {
+		p.release = p.Request.Release
+		return nil
+	}
+
+	app := p.Request.App
+	s := p.settings()
+	client := github.Client{Token: s.GitHubToken}
+	rel, err := client.ResolveRelease(app.RepoURL, app.Pin, s.ChannelFor(app) == config.ChannelPrerelease)
+	if err != nil {
+		return err
+	}
+	p.release = rel
+	return nil
+}
+
+func (p *Pipeline) selectAsset() error {
+	if p.Request.Asset != nil {
+		p.asset = p.Request.Asset
+		if isPackageAsset(p.asset.Name) {
+			p.method = config.InstallMethodPackage
+		} else {
+			p.method = config.InstallMethodBinary
+			p.binaryName = binary.GetBinaryName(*p.asset)
+			p.target = p.Request.Method
+		}
+		return nil
+	}
+
+	app := p.Request.App
+	method := p.Request.Method
+
+	// Updates stay on the method the app was originally installed with
+	if method == binary.Auto {
+		switch app.InstallMethod {
+		case config.InstallMethodHomebrew:
+			method = binary.Homebrew
+		case config.InstallMethodBinary:
+			return p.selectBinary(binary.MethodForPath(app.BinaryPath, p.settings().InstallDir))
+		case config.InstallMethodPackage:
+			return p.selectPackage()
+		}
+	}
+
+	switch method {
+	case binary.Auto:
+		// Prefer native packages, then Homebrew on macOS, then plain
+		// binaries, unless the install-method setting names another first
+		type candidate struct {
+			method string
+			try    func() error
+		}
+		preferred := p.settings().InstallMethod
+		candidates := []candidate{{config.InstallMethodPackage, p.selectPackage}}
+		if runtime.GOOS == "darwin" || preferred == config.InstallMethodHomebrew {
+			candidates = append(candidates, candidate{config.InstallMethodHomebrew, p.selectHomebrew})
+		}
+		candidates = append(candidates, candidate{config.InstallMethodBinary, func() error { return p.selectBinary(binary.Auto) }})
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].method == preferred && candidates[j].method != preferred
+		})
+
+		var errs []error
+		for _, c := range candidates {
+			err := c.try()
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	case binary.Homebrew:
+		return p.selectHomebrew()
+	default:
+		return p.selectBinary(method)
+	}
+}
+
+func (p *Pipeline) selectPackage() error {
+	assets, err := GetCompatibleAssets(p.release)
+	if err != nil {
+		return err
+	}
+
+	asset, err := SelectAsset(assets, p.Request.App.AssetPattern, p.release.TagName)
+	if err != nil {
+		return err
+	}
+
+	p.asset = asset
+	p.method = config.InstallMethodPackage
+	return nil
+}
+
+func (p *Pipeline) selectHomebrew() error {
+	if !homebrew.IsInstalled() {
+		return fmt.Errorf("homebrew not installed")
+	}
+
+	formula, err := homebrew.SearchFormula(p.Request.App.Name)
+	if err != nil {
+		return err
+	}
+
+	p.formula = formula
+	p.method = config.InstallMethodHomebrew
+	return nil
+}
+
+func (p *Pipeline) selectBinary(method binary.InstallMethod) error {
+	binaries := binary.DetectBinaryAssets(p.release)
+	if len(binaries) == 0 {
+		return fmt.Errorf("no binary assets found")
+	}
+
+	selected, err := SelectBinary(binaries, p.Request.App.AssetPattern, p.release.TagName)
+	if err != nil {
+		return err
+	}
+
+	p.asset = &selected.Asset
+	p.binaryName = selected.BinaryName
+	p.method = config.InstallMethodBinary
+	p.target = method
+	return nil
+}
+
+func (p *Pipeline) download() error {
+	url := p.asset.BrowserDownloadURL
+	if c, err := cache.Open(p.settings().CacheMaxSize); err == nil {
+		p.cache = c
+		expected := p.Request.Digest
+		if expected == "" && strings.HasPrefix(p.asset.Digest, "sha256:") {
+			expected = strings.TrimPrefix(p.asset.Digest, "sha256:")
+		}
+		if path, ok := c.Lookup(url, expected); ok {
+			fmt.Fprintf(p.Stdout, "Using cached %s\n", p.asset.Name)
+			p.assetPath = path
+			p.cached = true
+			return nil
+		}
+	}
+
+	p.assetPath = p.downloadPath()
+	if err := os.MkdirAll(filepath.Dir(p.assetPath), 0755); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Stdout, "Downloading %s...\n", url)
+	d := download.New()
+	d.OnProgress = p.OnProgress
+	return d.Fetch(context.Background(), url, p.assetPath)
+}
+
+// downloadPath is where the selected asset is downloaded to. A stable name
+// per URL lets an interrupted download resume next time.
+func (p *Pipeline) downloadPath() string {
+	sum := sha256.Sum256([]byte(p.asset.BrowserDownloadURL))
+	return filepath.Join(os.TempDir(), "autonomix-downloads", hex.EncodeToString(sum[:8])+"-"+filepath.Base(p.asset.Name))
+}
+
+func (p *Pipeline) verify() error {
+	digest, verified, err := VerifyFile(p.release, p.asset, p.assetPath)
+	if err != nil {
+		return err
+	}
+	if verified {
+		fmt.Fprintf(p.Stdout, "Verified sha256 %s\n", digest)
+	}
+	if p.Request.Digest != "" && !strings.EqualFold(p.Request.Digest, digest) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", p.asset.Name, p.Request.Digest, digest)
+	}
+	p.digest = digest
+
+	// Only verified downloads are added to the cache
+	if p.cache != nil && !p.cached {
+		if entry, err := p.cache.Store(p.asset.BrowserDownloadURL, p.asset.Name, p.assetPath); err == nil {
+			p.assetPath = p.cache.Path(*entry)
+			p.cached = true
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) extract() error {
+	if p.method != config.InstallMethodBinary {
+		return nil
+	}
+
+	path, err := binary.ExtractBinary(p.assetPath, p.binaryName)
+	if err != nil {
+		return err
+	}
+	p.binaryPath = path
+	return nil
+}
+
+func (p *Pipeline) install() (string, error) {
+	switch p.method {
+	case config.InstallMethodPackage:
+		cmd, err := GetInstallCmd(p.assetPath, p.binaryOptions().Escalator)
+		if err != nil {
+			return "", err
+		}
+		cmd.Stdin = p.Stdin
+		cmd.Stdout = p.Stdout
+		cmd.Stderr = p.Stderr
+
+		p.sudo = !privilege.IsRoot()
+
+		fmt.Fprintf(p.Stdout, "Installing %s...\n", p.asset.Name)
+		return "", cmd.Run()
+	case config.InstallMethodHomebrew:
+		return "", homebrew.InstallOfficial(p.formula)
+	case config.InstallMethodBinary:
+		res, err := binary.InstallBinary(p.binaryPath, p.binaryName, strings.TrimPrefix(p.release.TagName, "v"), p.target, p.binaryOptions())
+		if err != nil {
+			return "", err
+		}
+		p.sudo = res.RequiredSudo
+		fmt.Fprintln(p.Stdout, binary.GetInstallInstructions(res))
+		return res.Path, nil
+	}
+	return "", fmt.Errorf("nothing selected to install")
+}
+
+// binaryOptions returns the binary install options of the request's
+// settings.
+func (p *Pipeline) binaryOptions() binary.Options {
+	return binary.NewOptions(p.Request.Settings)
+}
+
+func (p *Pipeline) record(path string) *Result {
+	res := &Result{
+		Method:  p.method,
+		Version: strings.TrimPrefix(p.release.TagName, "v"),
+		Path:    path,
+		Digest:  p.digest,
+		Sudo:    p.sudo,
+	}
+
+	if p.asset != nil {
+		res.Asset = p.asset.Name
+		if p.Request.Asset != nil {
+			res.AssetPattern = PatternFromAsset(p.asset.Name, p.release.TagName)
+		}
+	}
+
+	switch p.method {
+	case config.InstallMethodHomebrew:
+		if ver, err := homebrew.GetInstalledVersion(p.formula); err == nil {
+			res.Version = ver
+		}
+		res.Message = fmt.Sprintf("Installed %s via Homebrew", p.formula)
+	case config.InstallMethodPackage:
+		res.Message = "Installed via package manager"
+	case config.InstallMethodBinary:
+		res.BinaryDigest, _ = FileDigest(path)
+		res.Message = fmt.Sprintf("Installed binary at %s", path)
+	}
+	return res
+}
+
+func isPackageAsset(name string) bool {
+	switch packages.DetectType(name) {
+	case packages.Deb, packages.Rpm, packages.Pacman:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+)
+
+func TestPipelinePlan(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, filepath.Join(home, "autonomix"))
+
+	asset := github.Asset{
+		Name:               "tool-linux-amd64.tar.gz",
+		BrowserDownloadURL: "https://example.com/tool-linux-amd64.tar.gz",
+	}
+	rel := &github.Release{TagName: "v1.2.3", Assets: []github.Asset{asset}}
+
+	p := New(Request{
+		App:     config.App{Name: "tool", RepoURL: "https://github.com/owner/tool"},
+		Method:  binary.UserPath,
+		Release: rel,
+		Asset:   &asset,
+	})
+	plan, err := p.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Release != "v1.2.3" || plan.Method != config.InstallMethodBinary || plan.URL != asset.BrowserDownloadURL {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if want := filepath.Join(home, ".local", "bin", binary.GetBinaryName(asset)); plan.Target != want {
+		t.Errorf("target = %s, want %s", plan.Target, want)
+	}
+
+	// Planning never downloads
+	if _, err := os.Stat(p.downloadPath()); !os.IsNotExist(err) {
+		t.Errorf("plan downloaded the asset")
+	}
+}
@@ -0,0 +1,133 @@
+package installer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tim/autonomix-cli/pkg/download"
+	"github.com/tim/autonomix-cli/pkg/github"
+)
+
+// FileDigest returns the hex encoded SHA-256 of the file at path.
+func FileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ExpectedDigest looks up the published SHA-256 for asset, either from the
+// digest GitHub reports for the asset or from a checksum file shipped with
+// the release. It returns an empty string when the release publishes none.
+func ExpectedDigest(release *github.Release, asset *github.Asset) (string, error) {
+	if strings.HasPrefix(asset.Digest, "sha256:") {
+		return strings.TrimPrefix(asset.Digest, "sha256:"), nil
+	}
+
+	sumAsset := findChecksumAsset(release, asset)
+	if sumAsset == nil {
+		return "", nil
+	}
+
+	tmp, err := os.CreateTemp("", "autonomix-checksums-*")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := download.New().Fetch(context.Background(), sumAsset.BrowserDownloadURL, tmp.Name()); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", sumAsset.Name, err)
+	}
+
+	return parseChecksumFile(tmp.Name(), asset.Name)
+}
+
+// VerifyFile checks the downloaded asset at path against the published digest.
+// It returns the actual digest and whether a published digest was available.
+func VerifyFile(release *github.Release, asset *github.Asset, path string) (string, bool, error) {
+	expected, err := ExpectedDigest(release, asset)
+	if err != nil {
+		return "", false, err
+	}
+
+	actual, err := FileDigest(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	if expected == "" {
+		return actual, false, nil
+	}
+	if !strings.EqualFold(expected, actual) {
+		return actual, true, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, expected, actual)
+	}
+	return actual, true, nil
+}
+
+func findChecksumAsset(release *github.Release, asset *github.Asset) *github.Asset {
+	// Per-asset checksum files take precedence over combined lists
+	for i, a := range release.Assets {
+		if a.Name == asset.Name+".sha256" || a.Name == asset.Name+".sha256sum" {
+			return &release.Assets[i]
+		}
+	}
+	for i, a := range release.Assets {
+		lower := strings.ToLower(a.Name)
+		if strings.Contains(lower, "checksums") || strings.Contains(lower, "sha256sums") {
+			return &release.Assets[i]
+		}
+	}
+	return nil
+}
+
+// parseChecksumFile reads "<hex>  <name>" lines as written by sha256sum and
+// GoReleaser. A file holding a single bare digest is also accepted.
+func parseChecksumFile(path, assetName string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var single string
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		lines++
+		if len(fields) == 1 {
+			single = fields[0]
+			continue
+		}
+		name := strings.TrimPrefix(fields[len(fields)-1], "*")
+		if filepath.Base(name) == assetName {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if lines == 1 && single != "" {
+		return single, nil
+	}
+	return "", nil
+}
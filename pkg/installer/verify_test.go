@@ -0,0 +1,31 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checksums.txt")
+	content := "aaaa  tool_1.0.0_linux_amd64.tar.gz\nbbbb *tool_1.0.0_linux_arm64.tar.gz\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"tool_1.0.0_linux_amd64.tar.gz": "aaaa",
+		"tool_1.0.0_linux_arm64.tar.gz": "bbbb",
+		"tool_1.0.0_darwin_arm64.zip":   "",
+	}
+	for name, want := range tests {
+		got, err := parseChecksumFile(path, name)
+		if err != nil {
+			t.Fatalf("parseChecksumFile(%s) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("parseChecksumFile(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
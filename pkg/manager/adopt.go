@@ -0,0 +1,152 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/history"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// How a Candidate's repository was found
+const (
+	MatchKnown    = "known"    // the knownRepos table
+	MatchHomepage = "homepage" // the package's homepage is on GitHub
+	MatchUser     = "user"     // given by the user
+)
+
+// Candidate is an installed tool that can be tracked without reinstalling.
+type Candidate struct {
+	Installed system.Installed
+	RepoURL   string
+	Match     string
+}
+
+// FindCandidates matches installed tools to GitHub repositories, through
+// the package's homepage or the knownRepos table. Packages matched by
+// homepage must have an executable of the same name in PATH, which leaves
+// out the many libraries hosted on GitHub. An executable named like an
+// installed package most likely belongs to it, so it is only matched
+// through that package. Repositories cfg already tracks are skipped, and
+// each repository and name is matched once: the first item wins, so
+// packages listed before PATH executables take precedence.
+func FindCandidates(items []system.Installed, cfg *config.Config) []Candidate {
+	onPath := make(map[string]bool)
+	packaged := make(map[string]bool)
+	for _, item := range items {
+		if item.Source == system.SourcePath {
+			onPath[strings.ToLower(item.Name)] = true
+		} else {
+			packaged[strings.ToLower(item.Name)] = true
+		}
+	}
+
+	var found []Candidate
+	seen := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	for _, item := range items {
+		name := strings.ToLower(item.Name)
+		if seenNames[name] || (item.Source == system.SourcePath && packaged[name]) {
+			continue
+		}
+		repo, match := repoFromHomepage(item.Homepage), MatchHomepage
+		if repo != "" && !onPath[name] {
+			repo = ""
+		}
+		if repo == "" {
+			repo, match = knownRepo(item), MatchKnown
+		}
+		if repo == "" {
+			continue
+		}
+		key := strings.ToLower(repo)
+		if seen[key] || cfg.FindApp(repo) != nil {
+			continue
+		}
+		seen[key] = true
+		seenNames[name] = true
+		found = append(found, Candidate{Installed: item, RepoURL: repo, Match: match})
+	}
+	return found
+}
+
+// repoFromHomepage returns the repository a GitHub homepage URL points at.
+func repoFromHomepage(homepage string) string {
+	if !strings.Contains(homepage, "github.com/") {
+		return ""
+	}
+	repo := strings.TrimSuffix(CleanRepoURL(homepage), ".git")
+	if strings.Count(strings.TrimPrefix(repo, "https://github.com/"), "/") != 1 {
+		return ""
+	}
+	return repo
+}
+
+// knownRepo looks item up in knownRepos: packages by source and name,
+// executables by name.
+func knownRepo(item system.Installed) string {
+	key := strings.ToLower(item.Name)
+	if item.Source != system.SourcePath {
+		key = string(item.Source) + ":" + key
+	}
+	if repo, ok := knownRepos[key]; ok {
+		return "https://github.com/" + repo
+	}
+	return ""
+}
+
+// AdoptApp tracks c as installed, with the install method and path of how
+// it was found, without downloading or installing anything. The app is
+// marked adopted, so removing it never deletes what was found.
+func AdoptApp(c Candidate) (*config.App, error) {
+	item := c.Installed
+	app := config.App{
+		Name:          item.Name,
+		RepoURL:       CleanRepoURL(c.RepoURL),
+		Version:       item.Version,
+		InstallStatus: config.StatusInstalled,
+		Adopted:       true,
+	}
+
+	switch item.Source {
+	case system.SourceDpkg, system.SourceRpm, system.SourcePacman:
+		app.InstallMethod = config.InstallMethodPackage
+	case system.SourceBrew:
+		app.InstallMethod = config.InstallMethodHomebrew
+	case system.SourcePath:
+		app.InstallMethod = config.InstallMethodBinary
+		app.BinaryPath = item.Path
+		app.BinaryDigest, _ = installer.FileDigest(item.Path)
+		if res, err := system.ProbeVersion(item.Path, system.VersionProbe{}); err == nil {
+			app.Version = res.Version
+		}
+	}
+	// Executable names and Flatpak IDs rarely read well as app names
+	if item.Source == system.SourcePath || item.Source == system.SourceFlatpak {
+		app.Name = getRepoName(app.RepoURL)
+	}
+	if app.Version == "" {
+		app.InstallStatus = ""
+	}
+
+	_, err := config.Update(func(cfg *config.Config) error {
+		if cfg.FindApp(app.RepoURL) != nil {
+			return fmt.Errorf("repository already tracked")
+		}
+		cfg.Apps = append(cfg.Apps, app)
+		return nil
+	})
+	history.Append(history.Entry{
+		Action: history.ActionAdd,
+		App:    app.Name,
+		Repo:   app.RepoURL,
+		To:     app.Version,
+		Method: app.InstallMethod,
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
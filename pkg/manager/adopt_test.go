@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+func TestFindCandidates(t *testing.T) {
+	cfg := &config.Config{Apps: []config.App{
+		{Name: "bat", RepoURL: "https://github.com/sharkdp/bat"},
+	}}
+	items := []system.Installed{
+		{Name: "ripgrep", Version: "14.1.0-1", Source: system.SourceDpkg, Homepage: "https://github.com/BurntSushi/ripgrep"},
+		{Name: "libgit2-1.7", Version: "1.7.2", Source: system.SourceDpkg, Homepage: "https://github.com/libgit2/libgit2"},
+		{Name: "fd-find", Version: "9.0.0-1", Source: system.SourceDpkg},
+		{Name: "jq", Version: "1.6-2.1", Source: system.SourceDpkg, Homepage: "https://github.com/stedolan/jq"},
+		{Name: "bat", Version: "0.24.0", Source: system.SourceDpkg},
+		{Name: "fzf", Version: "0.55.0", Source: system.SourceBrew, Homepage: "https://github.com/junegunn/fzf"},
+		{Name: "go-yq", Version: "4.44.3-1", Source: system.SourcePacman},
+		{Name: "rg", Source: system.SourcePath, Path: "/usr/bin/rg"},
+		{Name: "fzf", Source: system.SourcePath, Path: "/usr/local/bin/fzf"},
+		{Name: "lazygit", Source: system.SourcePath, Path: "/home/u/.local/bin/lazygit"},
+		{Name: "ls", Source: system.SourcePath, Path: "/usr/bin/ls"},
+		// Known as jqlang/jq, but the package already matched the name
+		{Name: "jq", Source: system.SourcePath, Path: "/usr/bin/jq"},
+		// Debian's delta and yq are different tools of the same name, and
+		// the executables belong to those packages
+		{Name: "delta", Version: "2006.08.03-11", Source: system.SourceDpkg},
+		{Name: "yq", Version: "3.1.0-3", Source: system.SourceDpkg},
+		{Name: "delta", Source: system.SourcePath, Path: "/usr/bin/delta"},
+		{Name: "yq", Source: system.SourcePath, Path: "/usr/bin/yq"},
+	}
+
+	got := FindCandidates(items, cfg)
+	want := []struct{ name, repo, match string }{
+		// Only rg is in PATH, so the homepage is not trusted
+		{"ripgrep", "https://github.com/BurntSushi/ripgrep", MatchKnown},
+		{"fd-find", "https://github.com/sharkdp/fd", MatchKnown},
+		{"jq", "https://github.com/stedolan/jq", MatchHomepage},
+		{"fzf", "https://github.com/junegunn/fzf", MatchHomepage},
+		{"go-yq", "https://github.com/mikefarah/yq", MatchKnown},
+		{"lazygit", "https://github.com/jesseduffield/lazygit", MatchKnown},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candidates %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Installed.Name != w.name || got[i].RepoURL != w.repo || got[i].Match != w.match {
+			t.Errorf("candidate %d = %s %s (%s), want %s %s (%s)",
+				i, got[i].Installed.Name, got[i].RepoURL, got[i].Match, w.name, w.repo, w.match)
+		}
+	}
+	if got[3].Installed.Source != system.SourceBrew {
+		t.Errorf("fzf came from %s, want the Homebrew formula over the PATH executable", got[2].Installed.Source)
+	}
+}
+
+func TestAdoptApp(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	path := filepath.Join(t.TempDir(), "lazygit")
+	script := "#!/bin/sh\necho lazygit 0.44.1\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := AdoptApp(Candidate{
+		Installed: system.Installed{Name: "lazygit", Source: system.SourcePath, Path: path},
+		RepoURL:   "https://github.com/jesseduffield/lazygit",
+		Match:     MatchKnown,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.InstallMethod != config.InstallMethodBinary || app.BinaryPath != path || app.BinaryDigest == "" || app.Version != "0.44.1" || !app.Adopted {
+		t.Errorf("adopted binary = %+v", app)
+	}
+	// Removing an adopted app stops tracking it but keeps the executable
+	if err := RemoveApp(*app, config.Settings{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("removing an adopted app deleted it: %v", err)
+	}
+	if _, err := AdoptApp(Candidate{
+		Installed: system.Installed{Name: "lazygit", Source: system.SourcePath, Path: path},
+		RepoURL:   "https://github.com/jesseduffield/lazygit",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := AdoptApp(Candidate{
+		Installed: system.Installed{Name: "ripgrep", Version: "14.1.0-1", Source: system.SourceDpkg},
+		RepoURL:   "https://github.com/BurntSushi/ripgrep",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.InstallMethod != config.InstallMethodPackage || pkg.Version != "14.1.0-1" || pkg.InstallStatus != config.StatusInstalled {
+		t.Errorf("adopted package = %+v", pkg)
+	}
+
+	if _, err := AdoptApp(Candidate{
+		Installed: system.Installed{Name: "rg", Source: system.SourceRpm, Version: "14.1.0"},
+		RepoURL:   "https://github.com/BurntSushi/ripgrep",
+	}); err == nil {
+		t.Error("adopting a tracked repository succeeded")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Apps) != 2 {
+		t.Errorf("tracked %d apps, want 2", len(cfg.Apps))
+	}
+}
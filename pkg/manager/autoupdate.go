@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+	"github.com/tim/autonomix-cli/pkg/semver"
+)
+
+// PolicyAllows reports whether app's update policy permits moving from its
+// installed version to tag. When it does not, the reason is returned.
+func PolicyAllows(app config.App, tag string) (bool, string) {
+	if ok, reason := autoPolicy(app); !ok {
+		return false, reason
+	}
+	policy := app.Policy()
+
+	if app.Version == "" {
+		return false, "not installed"
+	}
+	from, ok := semver.Parse(NormalizeVersion(app.Version))
+	if !ok {
+		return false, fmt.Sprintf("installed version %s is not a semantic version", app.Version)
+	}
+	to, ok := semver.Parse(NormalizeVersion(tag))
+	if !ok {
+		return false, fmt.Sprintf("release %s is not a semantic version", tag)
+	}
+	if semver.Compare(to, from) <= 0 {
+		return false, "up to date"
+	}
+
+	switch {
+	case policy == config.PolicyAutoPatch && (to.Major != from.Major || to.Minor != from.Minor):
+		return false, fmt.Sprintf("%s is not a patch release (policy %s)", tag, policy)
+	case policy == config.PolicyAutoMinor && to.Major != from.Major:
+		return false, fmt.Sprintf("%s is a major release (policy %s)", tag, policy)
+	}
+	return true, ""
+}
+
+// autoPolicy reports whether app's policy lets auto-update install
+// anything at all.
+func autoPolicy(app config.App) (bool, string) {
+	switch policy := app.Policy(); policy {
+	case config.PolicyManual, config.PolicyNotify:
+		return false, "update policy is " + policy
+	case config.PolicyAutoPatch, config.PolicyAutoMinor, config.PolicyAuto:
+		return true, ""
+	default:
+		return false, fmt.Sprintf("unknown update policy %q", policy)
+	}
+}
+
+// PlanAutoUpdate resolves app's release and decides whether auto-update
+// may install it: the policy must allow it and the install must not need
+// escalation, unless running as root or non-interactively, so it never
+// prompts. The plan is returned when the update is permitted; otherwise
+// skip says why not. Nothing is saved: callers record the release found
+// with RecordLatest unless they are only planning.
+func PlanAutoUpdate(app config.App, s config.Settings) (rel *github.Release, plan *installer.Plan, skip string, err error) {
+	// Skip apps that can never be updated without spending API requests
+	if ok, reason := autoPolicy(app); !ok {
+		return nil, nil, reason, nil
+	}
+
+	rel, err = LatestRelease(app, s)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to fetch release: %w", err)
+	}
+
+	if ok, reason := PolicyAllows(app, rel.TagName); !ok {
+		return rel, nil, reason, nil
+	}
+
+	plan, err = PlanInstall(rel, app, binary.Auto, s)
+	if err != nil {
+		return rel, nil, "", err
+	}
+	if plan.Sudo && !privilege.IsRoot() && !privilege.NonInteractive() {
+		return rel, plan, "install needs root, use --non-interactive to escalate without a prompt", nil
+	}
+	return rel, plan, "", nil
+}
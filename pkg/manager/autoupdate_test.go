@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	tests := []struct {
+		policy  string
+		version string
+		tag     string
+		want    bool
+	}{
+		{config.PolicyAutoPatch, "1.4.2", "v1.4.3", true},
+		{config.PolicyAutoPatch, "1.4.2", "v1.5.0", false},
+		{config.PolicyAutoMinor, "1.4.2", "v1.5.0", true},
+		{config.PolicyAutoMinor, "1.4.2", "v2.0.0", false},
+		{config.PolicyAuto, "1.4.2", "v2.0.0", true},
+		{config.PolicyAuto, "1.4.2", "v1.4.2", false},
+		{config.PolicyAuto, "1.4.2", "v1.4.1", false},
+		{config.PolicyAuto, "1.4.2", "v1.5.0-rc.1", true},
+		{config.PolicyAuto, "1.5.0-rc.1", "v1.5.0", true},
+		{config.PolicyAuto, "1.4.2", "nightly", false},
+		{config.PolicyAuto, "", "v1.0.0", false},
+		{config.PolicyNotify, "1.4.2", "v1.4.3", false},
+		{"", "1.4.2", "v1.4.3", false},
+		{config.PolicyManual, "1.4.2", "v1.4.3", false},
+		{"sometimes", "1.4.2", "v1.4.3", false},
+	}
+	for _, tt := range tests {
+		app := config.App{Name: "tool", Version: tt.version, UpdatePolicy: tt.policy}
+		got, reason := PolicyAllows(app, tt.tag)
+		if got != tt.want {
+			t.Errorf("PolicyAllows(%s, %s -> %s) = %v (%s), want %v", tt.policy, tt.version, tt.tag, got, reason, tt.want)
+		}
+		if !got && reason == "" {
+			t.Errorf("PolicyAllows(%s, %s -> %s) gave no reason", tt.policy, tt.version, tt.tag)
+		}
+	}
+}
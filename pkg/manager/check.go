@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/notify"
+)
+
+// UpdateAvailable reports whether app is installed and its latest known
+// release is newer than the installed version. Versions that are not
+// semantic versions count as an update whenever they differ.
+func UpdateAvailable(app config.App) bool {
+	if app.Version == "" || app.Latest == "" {
+		return false
+	}
+	if c, ok := compareVersions(app.Latest, app.Version); ok {
+		return c > 0
+	}
+	return NormalizeVersion(app.Latest) != NormalizeVersion(app.Version)
+}
+
+// CheckUpdates fetches the latest release of every app whose last check is
+// older than the check-interval setting, or of every app with force, and
+// returns the apps with an update available afterwards. Once GitHub
+// reports its rate limit used up, the remaining apps are skipped and keep
+// their last known release. A nil resolve uses LatestRelease with the
+// config's settings.
+func CheckUpdates(force bool, resolve ResolveFunc) ([]config.App, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	settings := cfg.Settings.WithDefaults()
+	if resolve == nil {
+		resolve = func(app config.App) (*github.Release, error) { return LatestRelease(app, settings) }
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		errs        []error
+		rateLimited atomic.Bool
+	)
+	for _, app := range cfg.Apps {
+		if !force && !CheckDue(app, settings) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(app config.App) {
+			defer wg.Done()
+			if rateLimited.Load() {
+				return
+			}
+
+			rel, err := resolve(app)
+			if err == nil {
+				_, err = RecordLatest(app.RepoURL, rel.TagName)
+			}
+			if err == nil {
+				return
+			}
+
+			var limit *github.RateLimitError
+			if errors.As(err, &limit) {
+				// Report the rate limit once rather than for every app
+				if rateLimited.Swap(true) {
+					return
+				}
+				err = limit
+			} else {
+				err = fmt.Errorf("%s: %w", app.Name, err)
+			}
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}(app)
+	}
+	wg.Wait()
+
+	cfg, err = config.Load()
+	if err != nil {
+		return nil, err
+	}
+	var updates []config.App
+	for _, app := range cfg.Apps {
+		if UpdateAvailable(app) {
+			updates = append(updates, app)
+		}
+	}
+	return updates, errors.Join(errs...)
+}
+
+// NotifyUpdates sends one notification summarizing updates. Apps with the
+// manual update policy are left out, and nothing is sent unless an update
+// was not announced by an earlier call, so a timer running the check
+// repeatedly does not nag about the same releases. It reports whether a
+// notification was sent.
+func NotifyUpdates(n notify.Notifier, updates []config.App) (bool, error) {
+	announce := make([]config.App, 0, len(updates))
+	for _, app := range updates {
+		if app.Policy() != config.PolicyManual {
+			announce = append(announce, app)
+		}
+	}
+	updates = announce
+
+	path, err := notifiedPath()
+	if err != nil {
+		return false, err
+	}
+
+	// Repo URL -> release tag last announced
+	notified := make(map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &notified)
+	}
+
+	current := make(map[string]string, len(updates))
+	fresh := false
+	for _, app := range updates {
+		key := strings.ToLower(app.RepoURL)
+		current[key] = app.Latest
+		if notified[key] != app.Latest {
+			fresh = true
+		}
+	}
+
+	if fresh {
+		summary := "1 update available"
+		if len(updates) > 1 {
+			summary = fmt.Sprintf("%d updates available", len(updates))
+		}
+		lines := make([]string, 0, len(updates))
+		for _, app := range updates {
+			lines = append(lines, fmt.Sprintf("%s %s → %s", app.Name, app.Version, app.Latest))
+		}
+		sort.Strings(lines)
+		if err := n.Notify(summary, strings.Join(lines, "\n")); err != nil {
+			return false, err
+		}
+	}
+
+	// Forget updates that were installed so a later release is announced
+	data, err := json.Marshal(current)
+	if err != nil {
+		return fresh, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fresh, err
+	}
+	return fresh, os.WriteFile(path, data, 0644)
+}
+
+func notifiedPath() (string, error) {
+	dir, err := config.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "notified.json"), nil
+}
@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/github"
+)
+
+type fakeNotifier struct {
+	summaries []string
+	bodies    []string
+}
+
+func (f *fakeNotifier) Notify(summary, body string) error {
+	f.summaries = append(f.summaries, summary)
+	f.bodies = append(f.bodies, body)
+	return nil
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	tests := []struct {
+		version, latest string
+		want            bool
+	}{
+		{"1.7.0", "jq-1.7.1", true},
+		{"1.7.1", "jq-1.7.1", false},
+		{"14.1.0-1", "14.1.0", false},
+		// A newer install than the latest release is not an update
+		{"0.56.0", "v0.55.0", false},
+		{"1.0.0-rc1", "v1.0.0", true},
+		// Versions that do not parse fall back to comparing strings
+		{"nightly-a", "nightly-b", true},
+		{"", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		app := config.App{Version: tt.version, Latest: tt.latest}
+		if got := UpdateAvailable(app); got != tt.want {
+			t.Errorf("UpdateAvailable(%q -> %q) = %v, want %v", tt.version, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUpdates(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	_, err := config.Update(func(c *config.Config) error {
+		c.Apps = []config.App{
+			{Name: "rg", RepoURL: "https://github.com/BurntSushi/ripgrep", Version: "14.1.0"},
+			{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", Version: "0.55.0"},
+			{Name: "bat", RepoURL: "https://github.com/sharkdp/bat", Version: "0.24.0", Latest: "v0.24.0", LastChecked: "2999-01-01T00:00:00Z"},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	resolve := func(app config.App) (*github.Release, error) {
+		calls.Add(1)
+		switch app.Name {
+		case "rg":
+			return &github.Release{TagName: "14.1.1"}, nil
+		case "fzf":
+			return &github.Release{TagName: "v0.55.0"}, nil
+		}
+		return nil, fmt.Errorf("unexpected check of %s", app.Name)
+	}
+
+	updates, err := CheckUpdates(false, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected only the two due apps to be checked, got %d checks", calls.Load())
+	}
+	if len(updates) != 1 || updates[0].Name != "rg" || updates[0].Latest != "14.1.1" {
+		t.Errorf("unexpected updates %+v", updates)
+	}
+
+	// A rate limit is reported once and the known updates are still returned
+	limited := func(config.App) (*github.Release, error) {
+		return nil, fmt.Errorf("release not found: %w", &github.RateLimitError{})
+	}
+	updates, err = CheckUpdates(true, limited)
+	if err == nil || strings.Count(err.Error(), "rate limit") != 1 {
+		t.Errorf("expected one rate limit error, got %v", err)
+	}
+	if len(updates) != 1 {
+		t.Errorf("expected the earlier update to be kept, got %+v", updates)
+	}
+}
+
+func TestNotifyUpdates(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	n := &fakeNotifier{}
+
+	rg := config.App{Name: "rg", RepoURL: "https://github.com/BurntSushi/ripgrep", Version: "14.1.0", Latest: "14.1.1"}
+	fzf := config.App{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", Version: "0.54.0", Latest: "v0.55.0"}
+
+	steps := []struct {
+		updates []config.App
+		sent    bool
+	}{
+		{[]config.App{rg}, true},
+		{[]config.App{rg}, false},     // already announced
+		{[]config.App{rg, fzf}, true}, // fzf is new
+		{nil, false},                  // everything installed
+		{[]config.App{rg}, true},      // announced again after being cleared
+	}
+	for i, step := range steps {
+		sent, err := NotifyUpdates(n, step.updates)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sent != step.sent {
+			t.Errorf("step %d: sent = %v, want %v", i, sent, step.sent)
+		}
+	}
+
+	if len(n.summaries) != 3 || n.summaries[1] != "2 updates available" {
+		t.Fatalf("unexpected notifications %q", n.summaries)
+	}
+	if !strings.Contains(n.bodies[1], "fzf 0.54.0 → v0.55.0") {
+		t.Errorf("body does not list fzf: %q", n.bodies[1])
+	}
+}
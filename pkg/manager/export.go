@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+// Export returns the tracked apps as a manifest. Only portable fields are
+// kept: install paths, versions and status belong to this machine.
+func Export(cfg *config.Config) *manifest.Manifest {
+	m := &manifest.Manifest{Apps: []manifest.App{}}
+	for _, app := range cfg.Apps {
+		m.Apps = append(m.Apps, manifest.App{
+			Repo:         manifest.ShortRepo(app.RepoURL),
+			Name:         app.Name,
+			Version:      app.Pin,
+			Channel:      app.Channel,
+			AssetPattern: app.AssetPattern,
+		})
+	}
+	return m
+}
+
+// ImportApp tracks entry with its pin, channel and asset rules. With
+// install, an app that is not installed yet is installed as well; apps
+// already installed are left on their version.
+func ImportApp(entry manifest.App, install bool, s config.Settings) (*config.App, error) {
+	desired := manifestApp(entry)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.FindApp(desired.RepoURL) == nil {
+		if _, err := AddApp(desired.RepoURL, s); err != nil {
+			return nil, err
+		}
+	}
+	if err := configure(desired); err != nil {
+		return nil, err
+	}
+
+	cfg, err = config.Load()
+	if err != nil {
+		return nil, err
+	}
+	app := cfg.FindApp(desired.RepoURL)
+	if app == nil {
+		return nil, fmt.Errorf("%s is no longer tracked", desired.RepoURL)
+	}
+	if !install || app.Version != "" {
+		return app, nil
+	}
+
+	method := applyMethod(app, entry.Method)
+	return InstallApp(nil, *app, method, s)
+}
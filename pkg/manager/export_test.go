@@ -0,0 +1,35 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+)
+
+func TestExport(t *testing.T) {
+	cfg := &config.Config{Apps: []config.App{{
+		Name:          "rg",
+		RepoURL:       "https://github.com/BurntSushi/ripgrep",
+		Version:       "14.1.0",
+		Pin:           "14.1.0",
+		AssetPattern:  "re:musl",
+		InstallMethod: config.InstallMethodBinary,
+		BinaryPath:    "/home/me/.local/bin/rg",
+		InstallStatus: config.StatusInstalled,
+	}}}
+
+	m := Export(cfg)
+	if len(m.Apps) != 1 {
+		t.Fatalf("expected one app, got %d", len(m.Apps))
+	}
+	got := m.Apps[0]
+	if got.Repo != "BurntSushi/ripgrep" || got.Name != "rg" || got.Version != "14.1.0" || got.AssetPattern != "re:musl" {
+		t.Errorf("unexpected export %+v", got)
+	}
+	if got.Method != "" {
+		t.Errorf("install method is machine specific and should not be exported, got %q", got.Method)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("export does not validate: %v", err)
+	}
+}
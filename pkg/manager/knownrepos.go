@@ -0,0 +1,127 @@
+package manager
+
+// knownRepos maps popular tools to their GitHub repositories, for tools
+// whose package metadata does not link there. Packages are keyed by
+// source and package name, since a package name can mean different
+// software in different repositories: Debian's delta is delta debugging
+// and its yq is a jq wrapper. Bare names match executables in PATH. Names
+// are lower case.
+var knownRepos = map[string]string{
+	// Debian and Ubuntu
+	"dpkg:bat":       "sharkdp/bat",
+	"dpkg:btop":      "aristocratos/btop",
+	"dpkg:direnv":    "direnv/direnv",
+	"dpkg:du-dust":   "bootandy/dust",
+	"dpkg:eza":       "eza-community/eza",
+	"dpkg:fd-find":   "sharkdp/fd",
+	"dpkg:fzf":       "junegunn/fzf",
+	"dpkg:gh":        "cli/cli",
+	"dpkg:git-delta": "dandavison/delta",
+	"dpkg:hyperfine": "sharkdp/hyperfine",
+	"dpkg:jq":        "jqlang/jq",
+	"dpkg:just":      "casey/just",
+	"dpkg:lsd":       "lsd-rs/lsd",
+	"dpkg:neovim":    "neovim/neovim",
+	"dpkg:ripgrep":   "BurntSushi/ripgrep",
+	"dpkg:sd":        "chmln/sd",
+	"dpkg:tealdeer":  "tealdeer-rs/tealdeer",
+	"dpkg:zoxide":    "ajeetdsouza/zoxide",
+
+	// Fedora and openSUSE
+	"rpm:bat":       "sharkdp/bat",
+	"rpm:btop":      "aristocratos/btop",
+	"rpm:direnv":    "direnv/direnv",
+	"rpm:eza":       "eza-community/eza",
+	"rpm:fd-find":   "sharkdp/fd",
+	"rpm:fzf":       "junegunn/fzf",
+	"rpm:gh":        "cli/cli",
+	"rpm:git-delta": "dandavison/delta",
+	"rpm:helix":     "helix-editor/helix",
+	"rpm:hyperfine": "sharkdp/hyperfine",
+	"rpm:jq":        "jqlang/jq",
+	"rpm:just":      "casey/just",
+	"rpm:lsd":       "lsd-rs/lsd",
+	"rpm:neovim":    "neovim/neovim",
+	"rpm:ripgrep":   "BurntSushi/ripgrep",
+	"rpm:sd":        "chmln/sd",
+	"rpm:tealdeer":  "tealdeer-rs/tealdeer",
+	"rpm:zoxide":    "ajeetdsouza/zoxide",
+
+	// Arch Linux
+	"pacman:act":        "nektos/act",
+	"pacman:atuin":      "atuinsh/atuin",
+	"pacman:bat":        "sharkdp/bat",
+	"pacman:bottom":     "ClementTsang/bottom",
+	"pacman:btop":       "aristocratos/btop",
+	"pacman:chezmoi":    "twpayne/chezmoi",
+	"pacman:difftastic": "Wilfred/difftastic",
+	"pacman:direnv":     "direnv/direnv",
+	"pacman:dust":       "bootandy/dust",
+	"pacman:eza":        "eza-community/eza",
+	"pacman:fd":         "sharkdp/fd",
+	"pacman:fzf":        "junegunn/fzf",
+	"pacman:git-delta":  "dandavison/delta",
+	"pacman:github-cli": "cli/cli",
+	"pacman:glow":       "charmbracelet/glow",
+	"pacman:go-yq":      "mikefarah/yq",
+	"pacman:helix":      "helix-editor/helix",
+	"pacman:hyperfine":  "sharkdp/hyperfine",
+	"pacman:jq":         "jqlang/jq",
+	"pacman:just":       "casey/just",
+	"pacman:k9s":        "derailed/k9s",
+	"pacman:lazygit":    "jesseduffield/lazygit",
+	"pacman:lsd":        "lsd-rs/lsd",
+	"pacman:mise":       "jdx/mise",
+	"pacman:neovim":     "neovim/neovim",
+	"pacman:procs":      "dalance/procs",
+	"pacman:ripgrep":    "BurntSushi/ripgrep",
+	"pacman:sd":         "chmln/sd",
+	"pacman:starship":   "starship/starship",
+	"pacman:tealdeer":   "tealdeer-rs/tealdeer",
+	"pacman:tokei":      "XAMPPRocky/tokei",
+	"pacman:yazi":       "sxyazi/yazi",
+	"pacman:zellij":     "zellij-org/zellij",
+	"pacman:zoxide":     "ajeetdsouza/zoxide",
+
+	// Homebrew
+	"brew:git-delta": "dandavison/delta",
+	"brew:yq":        "mikefarah/yq",
+
+	// Executables in PATH
+	"act":        "nektos/act",
+	"atuin":      "atuinsh/atuin",
+	"bat":        "sharkdp/bat",
+	"batcat":     "sharkdp/bat",
+	"btm":        "ClementTsang/bottom",
+	"btop":       "aristocratos/btop",
+	"chezmoi":    "twpayne/chezmoi",
+	"delta":      "dandavison/delta",
+	"difft":      "Wilfred/difftastic",
+	"direnv":     "direnv/direnv",
+	"dust":       "bootandy/dust",
+	"eza":        "eza-community/eza",
+	"fd":         "sharkdp/fd",
+	"fdfind":     "sharkdp/fd",
+	"fzf":        "junegunn/fzf",
+	"gh":         "cli/cli",
+	"glow":       "charmbracelet/glow",
+	"hx":         "helix-editor/helix",
+	"hyperfine":  "sharkdp/hyperfine",
+	"jq":         "jqlang/jq",
+	"just":       "casey/just",
+	"k9s":        "derailed/k9s",
+	"lazydocker": "jesseduffield/lazydocker",
+	"lazygit":    "jesseduffield/lazygit",
+	"lsd":        "lsd-rs/lsd",
+	"mise":       "jdx/mise",
+	"nvim":       "neovim/neovim",
+	"procs":      "dalance/procs",
+	"rg":         "BurntSushi/ripgrep",
+	"sd":         "chmln/sd",
+	"starship":   "starship/starship",
+	"tokei":      "XAMPPRocky/tokei",
+	"yazi":       "sxyazi/yazi",
+	"yq":         "mikefarah/yq",
+	"zellij":     "zellij-org/zellij",
+	"zoxide":     "ajeetdsouza/zoxide",
+}
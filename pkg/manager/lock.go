@@ -0,0 +1,210 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+// LockApp resolves entry the way a fresh install on this machine would and
+// returns its lock record. The asset is downloaded and verified to learn
+// its SHA-256; it stays in the download cache for the install.
+func LockApp(entry manifest.App, s config.Settings) (*manifest.LockedApp, error) {
+	app := manifestApp(entry)
+	if app.Name == "" {
+		app.Name = getRepoName(app.RepoURL)
+	}
+	method := applyMethod(&app, entry.Method)
+	if method == binary.Homebrew {
+		return nil, fmt.Errorf("homebrew installs cannot be locked")
+	}
+
+	rel, err := LatestRelease(app, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve release: %w", err)
+	}
+
+	p := installer.New(installer.Request{App: app, Release: rel, Method: method, Settings: s})
+	defer p.Cleanup()
+	if err := p.Prepare(); err != nil {
+		return nil, err
+	}
+	if p.Method() == config.InstallMethodHomebrew {
+		return nil, fmt.Errorf("homebrew installs cannot be locked, set its method to binary or package")
+	}
+
+	asset := p.Asset()
+	return &manifest.LockedApp{
+		Repo:         entry.Repo,
+		Version:      entry.Version,
+		Channel:      entry.Channel,
+		AssetPattern: entry.AssetPattern,
+		Method:       entry.Method,
+		Tag:          rel.TagName,
+		Assets: []manifest.LockedAsset{{
+			Platform: manifest.Platform(),
+			Name:     asset.Name,
+			URL:      asset.BrowserDownloadURL,
+			SHA256:   p.Digest(),
+		}},
+	}, nil
+}
+
+// InstallLocked installs exactly what locked records for this platform and
+// reports whether it installed anything. An app already installed from the
+// locked tag and asset is left as it is. Otherwise the release must still
+// carry the locked tag and asset at the locked URL, and the download must
+// match the locked SHA-256; anything else fails before the install stage.
+// The app is tracked with entry's settings.
+func InstallLocked(entry manifest.App, locked *manifest.LockedApp, s config.Settings) (*config.App, bool, error) {
+	want := locked.Asset(manifest.Platform())
+	if want == nil {
+		return nil, false, fmt.Errorf("nothing locked for %s", manifest.Platform())
+	}
+
+	desired := manifestApp(entry)
+	if app, ok := lockedInstalled(desired.RepoURL, locked.Tag, want.SHA256); ok {
+		if err := configure(desired); err != nil {
+			return nil, false, err
+		}
+		configured := withManifest(app, desired)
+		return &configured, false, nil
+	}
+
+	rel, err := github.Client{Token: s.GitHubToken}.GetReleaseByTag(desired.RepoURL, locked.Tag)
+	if err != nil {
+		return nil, false, err
+	}
+	if rel.TagName != locked.Tag {
+		return nil, false, fmt.Errorf("release %s not found, got %s", locked.Tag, rel.TagName)
+	}
+
+	var asset *github.Asset
+	for i := range rel.Assets {
+		if rel.Assets[i].Name == want.Name {
+			asset = &rel.Assets[i]
+			break
+		}
+	}
+	if asset == nil {
+		return nil, false, fmt.Errorf("release %s no longer has asset %s", locked.Tag, want.Name)
+	}
+	if asset.BrowserDownloadURL != want.URL {
+		return nil, false, fmt.Errorf("asset %s moved from %s to %s", want.Name, want.URL, asset.BrowserDownloadURL)
+	}
+
+	app, method, err := trackLocked(desired, entry.Method, rel, s)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, installErr := installer.New(installer.Request{
+		App:      app,
+		Method:   method,
+		Release:  rel,
+		Asset:    asset,
+		Digest:   want.SHA256,
+		Settings: s,
+	}).Run()
+
+	updated, err := RecordInstall(app.RepoURL, res, installErr)
+	if err != nil {
+		return nil, false, err
+	}
+	if installErr != nil {
+		return updated, false, installErr
+	}
+
+	// Installing an explicit asset remembers it as a pattern; the manifest's
+	// asset rules stay authoritative
+	if err := configure(desired); err != nil {
+		return nil, false, err
+	}
+	configured := withManifest(*updated, desired)
+	return &configured, true, nil
+}
+
+// lockedInstalled returns the tracked app for repoURL when its recorded
+// version is tag and it was installed from an asset with the given
+// SHA-256. A binary that changed since it was installed does not count.
+func lockedInstalled(repoURL, tag, digest string) (config.App, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.App{}, false
+	}
+	app := cfg.FindApp(repoURL)
+	if app == nil || !Installed(*app) || !sameVersion(app.Version, tag) ||
+		app.AssetDigest == "" || !strings.EqualFold(app.AssetDigest, digest) {
+		return config.App{}, false
+	}
+	if app.InstallMethod == config.InstallMethodBinary {
+		current, err := installer.FileDigest(app.BinaryPath)
+		if err != nil || (app.BinaryDigest != "" && current != app.BinaryDigest) {
+			return config.App{}, false
+		}
+	}
+	return *app, true
+}
+
+// withManifest returns app with the manifest-controlled fields configure
+// stores.
+func withManifest(app, desired config.App) config.App {
+	if desired.Name != "" {
+		app.Name = desired.Name
+	}
+	if desired.Pin != "" {
+		app.Pin = desired.Pin
+	}
+	app.Channel = desired.Channel
+	app.AssetPattern = desired.AssetPattern
+	return app
+}
+
+// trackLocked makes sure app is tracked and returns the tracked app with
+// the install method to use.
+func trackLocked(app config.App, method string, rel *github.Release, s config.Settings) (config.App, binary.InstallMethod, error) {
+	var tracked config.App
+	_, err := config.Update(func(c *config.Config) error {
+		existing := c.FindApp(app.RepoURL)
+		if existing == nil {
+			if app.Name == "" {
+				app.Name = getAppName(app.RepoURL, rel)
+			}
+			app.Latest = rel.TagName
+			app.LastChecked = time.Now().UTC().Format(time.RFC3339)
+			c.Apps = append(c.Apps, app)
+			existing = &c.Apps[len(c.Apps)-1]
+		}
+		tracked = *existing
+		return nil
+	})
+	if err != nil {
+		return config.App{}, binary.Auto, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	// Updates stay on the method the app was installed with
+	switch {
+	case tracked.InstallMethod == config.InstallMethodBinary && tracked.BinaryPath != "":
+		return tracked, binary.MethodForPath(tracked.BinaryPath, s.InstallDir), nil
+	case tracked.Version == "":
+		return tracked, applyMethod(&tracked, method), nil
+	}
+	return tracked, binary.Auto, nil
+}
+
+// manifestApp returns the tracked form of a manifest entry.
+func manifestApp(entry manifest.App) config.App {
+	return config.App{
+		Name:         entry.Name,
+		RepoURL:      CleanRepoURL(manifest.RepoURL(entry.Repo)),
+		Pin:          entry.Version,
+		Channel:      entry.Channel,
+		AssetPattern: entry.AssetPattern,
+	}
+}
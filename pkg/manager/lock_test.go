@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+func TestInstallLockedSkipsMatchingInstall(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	_, err := config.Update(func(c *config.Config) error {
+		c.Apps = append(c.Apps, config.App{
+			Name:          "rg",
+			RepoURL:       "https://github.com/BurntSushi/ripgrep",
+			Version:       "14.1.0",
+			InstallMethod: config.InstallMethodPackage,
+			InstallStatus: config.StatusInstalled,
+			AssetDigest:   "abc123",
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := manifest.App{Repo: "BurntSushi/ripgrep", Version: "14.1.0"}
+	locked := &manifest.LockedApp{
+		Repo: "BurntSushi/ripgrep",
+		Tag:  "v14.1.0",
+		Assets: []manifest.LockedAsset{{
+			Platform: manifest.Platform(),
+			Name:     "ripgrep_14.1.0_amd64.deb",
+			URL:      "https://example.invalid/ripgrep_14.1.0_amd64.deb",
+			SHA256:   "ABC123",
+		}},
+	}
+
+	// A matching install never reaches GitHub or the installer
+	app, installed, err := InstallLocked(entry, locked, config.Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if installed || app.Version != "14.1.0" || app.Pin != "14.1.0" {
+		t.Fatalf("unexpected result installed=%v app=%+v", installed, app)
+	}
+}
+
+func TestLockedInstalledRequiresDigest(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	_, err := config.Update(func(c *config.Config) error {
+		c.Apps = append(c.Apps, config.App{
+			Name:          "rg",
+			RepoURL:       "https://github.com/BurntSushi/ripgrep",
+			Version:       "14.1.0",
+			InstallMethod: config.InstallMethodPackage,
+			InstallStatus: config.StatusInstalled,
+			AssetDigest:   "abc123",
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := "https://github.com/BurntSushi/ripgrep"
+	if _, ok := lockedInstalled(repo, "v14.1.0", "def456"); ok {
+		t.Error("different asset digest treated as installed")
+	}
+	if _, ok := lockedInstalled(repo, "v14.2.0", "abc123"); ok {
+		t.Error("different version treated as installed")
+	}
+}
@@ -2,14 +2,20 @@ package manager
 
 import (
 	"fmt"
-	"runtime"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/tim/autonomix-cli/config"
 	"github.com/tim/autonomix-cli/pkg/binary"
 	"github.com/tim/autonomix-cli/pkg/github"
-	"github.com/tim/autonomix-cli/pkg/homebrew"
+	"github.com/tim/autonomix-cli/pkg/history"
 	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/semver"
 	"github.com/tim/autonomix-cli/pkg/system"
 )
 
@@ -18,46 +24,134 @@ type AddResult struct {
 	Created bool
 }
 
-func AddApp(cfg *config.Config, repoURL string) (*AddResult, error) {
-	repoURL = cleanRepoURL(repoURL)
+// AddApp starts tracking repoURL and records it in the config.
+func AddApp(repoURL string, s config.Settings) (*AddResult, error) {
+	repoURL = CleanRepoURL(repoURL)
 
-	for _, app := range cfg.Apps {
-		if strings.EqualFold(app.RepoURL, repoURL) {
-			return &AddResult{App: app, Created: false}, fmt.Errorf("repository already tracked")
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if app := cfg.FindApp(repoURL); app != nil {
+		return &AddResult{App: *app, Created: false}, fmt.Errorf("repository already tracked")
+	}
+
+	newApp, _, err := planAdd(repoURL, s, system.CheckInstalled)
+	if err != nil {
+		history.Append(history.Entry{Action: history.ActionAdd, Repo: repoURL}, err)
+		return nil, err
+	}
+
+	// Another process may have added the repo while we were fetching
+	_, err = config.Update(func(c *config.Config) error {
+		if c.FindApp(repoURL) != nil {
+			return fmt.Errorf("repository already tracked")
 		}
+		c.Apps = append(c.Apps, *newApp)
+		return nil
+	})
+	history.Append(history.Entry{
+		Action: history.ActionAdd,
+		App:    newApp.Name,
+		Repo:   repoURL,
+		To:     newApp.Version,
+	}, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
 
-	rel, err := github.GetLatestRelease(repoURL)
+	return &AddResult{App: *newApp, Created: true}, nil
+}
+
+// PlanAdd returns the app AddApp would track for repoURL and its release,
+// without saving or running anything: a binary already in PATH is not
+// asked for its version, so it is marked installed with an empty version.
+func PlanAdd(repoURL string, s config.Settings) (*config.App, *github.Release, error) {
+	return planAdd(CleanRepoURL(repoURL), s, system.FindInstalled)
+}
+
+// planAdd builds the app tracked for repoURL, finding an existing install
+// with check.
+func planAdd(repoURL string, s config.Settings, check func(string) (string, packages.Type, bool)) (*config.App, *github.Release, error) {
+	rel, err := LatestRelease(config.App{RepoURL: repoURL}, s)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch release: %w", err)
 	}
 
 	appName := getAppName(repoURL, rel)
 	repoName := getRepoName(repoURL)
 
 	newApp := config.App{
-		Name:    appName,
-		RepoURL: repoURL,
-		Latest:  rel.TagName,
+		Name:        appName,
+		RepoURL:     repoURL,
+		Latest:      rel.TagName,
+		LastChecked: time.Now().UTC().Format(time.RFC3339),
 	}
 
-	if ver, _, installed := system.CheckInstalled(appName); installed {
+	// A dry run does not probe binaries in PATH, so an installed app may
+	// have no version
+	ver, _, installed := check(appName)
+	if !installed && repoName != "" && repoName != appName {
+		ver, _, installed = check(repoName)
+	}
+	if installed {
 		newApp.Version = ver
-	} else if repoName != "" && repoName != appName {
-		if ver, _, installed := system.CheckInstalled(repoName); installed {
-			newApp.Version = ver
-		}
+		newApp.InstallStatus = config.StatusInstalled
 	}
+	return &newApp, rel, nil
+}
 
-	cfg.Apps = append(cfg.Apps, newApp)
-	if err := config.Save(cfg); err != nil {
-		return nil, fmt.Errorf("failed to save config: %w", err)
+var (
+	limiterOnce sync.Once
+	limiter     chan struct{}
+)
+
+// LatestRelease fetches the release app should be on: its pin, or the
+// newest release on its channel. At most the parallelism setting's worth
+// of requests run at once, as set by the first call.
+func LatestRelease(app config.App, s config.Settings) (*github.Release, error) {
+	s = s.WithDefaults()
+	limiterOnce.Do(func() {
+		limiter = make(chan struct{}, s.Parallelism)
+	})
+	limiter <- struct{}{}
+	defer func() { <-limiter }()
+
+	client := github.Client{Token: s.GitHubToken}
+	return client.ResolveRelease(app.RepoURL, app.Pin, s.ChannelFor(app) == config.ChannelPrerelease)
+}
+
+// CheckDue reports whether app's last release check is older than the
+// check-interval setting.
+func CheckDue(app config.App, settings config.Settings) bool {
+	checked, err := time.Parse(time.RFC3339, app.LastChecked)
+	if err != nil {
+		return true
 	}
+	return time.Since(checked) >= settings.CheckEvery()
+}
 
-	return &AddResult{App: newApp, Created: true}, nil
+// RecordLatest stores the latest release tag found for repoURL.
+func RecordLatest(repoURL, tag string) (*config.App, error) {
+	var updated config.App
+	_, err := config.Update(func(c *config.Config) error {
+		app := c.FindApp(repoURL)
+		if app == nil {
+			return fmt.Errorf("%s is no longer tracked", repoURL)
+		}
+		app.Latest = tag
+		app.LastChecked = time.Now().UTC().Format(time.RFC3339)
+		updated = *app
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
-func cleanRepoURL(url string) string {
+// CleanRepoURL reduces a GitHub URL to https://github.com/owner/repo.
+func CleanRepoURL(url string) string {
 	if strings.Contains(url, "github.com/") {
 		parts := strings.Split(url, "github.com/")
 		if len(parts) == 2 {
@@ -89,98 +183,214 @@ func getRepoName(repoURL string) string {
 	return ""
 }
 
-func InstallApp(rel *github.Release, app *config.App, method binary.InstallMethod) error {
-	if method != binary.Auto {
-		return installWithMethod(rel, app, method)
-	}
+// InstallApp installs the release for app through the install pipeline and
+// records the outcome in the config. A nil release installs the latest one.
+// The updated app is returned even when the install fails.
+func InstallApp(rel *github.Release, app config.App, method binary.InstallMethod, s config.Settings) (*config.App, error) {
+	res, installErr := installer.New(installer.Request{App: app, Release: rel, Method: method, Settings: s}).Run()
 
-	// Auto: try package, homebrew, then binary
-	if err := tryPackageInstall(rel, app); err == nil {
-		app.InstallStatus = config.StatusInstalled
-		return nil
+	updated, err := RecordInstall(app.RepoURL, res, installErr)
+	if err != nil {
+		return nil, err
 	}
+	return updated, installErr
+}
 
-	if runtime.GOOS == "darwin" {
-		if err := tryHomebrewInstall(app); err == nil {
-			app.InstallStatus = config.StatusInstalled
-			return nil
+// RecordInstall writes an install outcome for the app tracking repoURL to
+// the config and the history, and returns the updated app.
+func RecordInstall(repoURL string, res *installer.Result, installErr error) (*config.App, error) {
+	var previous, updated config.App
+	_, err := config.Update(func(c *config.Config) error {
+		app := c.FindApp(repoURL)
+		if app == nil {
+			return fmt.Errorf("%s is no longer tracked", repoURL)
+		}
+		previous = *app
+		if installErr != nil {
+			installer.ApplyError(app, installErr)
+		} else {
+			res.Apply(app)
 		}
+		updated = *app
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
+	recordInstallHistory(previous, res, installErr)
+	return &updated, nil
+}
 
-	if err := tryBinaryInstall(rel, app, binary.Auto); err != nil {
-		app.InstallStatus = config.StatusFailed
-		app.InstallError = err.Error()
-		return err
+func recordInstallHistory(previous config.App, res *installer.Result, installErr error) {
+	entry := history.Entry{
+		Action: history.ActionInstall,
+		App:    previous.Name,
+		Repo:   previous.RepoURL,
+		From:   previous.Version,
+		Method: previous.InstallMethod,
+	}
+	if previous.Version != "" {
+		entry.Action = history.ActionUpdate
+	}
+	if res != nil {
+		entry.To = res.Version
+		entry.Asset = res.Asset
+		entry.Digest = res.Digest
+		entry.Method = res.Method
+		entry.Sudo = res.Sudo
 	}
+	history.Append(entry, installErr)
+}
 
-	app.InstallStatus = config.StatusInstalled
+// UninstallApp removes what autonomix installed for app. Packages
+// installed through the system package manager and adopted apps are left
+// alone.
+func UninstallApp(app config.App, s config.Settings) error {
+	if app.Adopted {
+		return nil
+	}
+	switch app.InstallMethod {
+	case config.InstallMethodHomebrew:
+		if err := exec.Command("brew", "uninstall", app.Name).Run(); err != nil {
+			return fmt.Errorf("brew uninstall failed: %w", err)
+		}
+	case config.InstallMethodBinary:
+		if app.BinaryPath != "" {
+			err := os.Remove(app.BinaryPath)
+			if os.IsPermission(err) {
+				err = binary.NewOptions(s).Escalator.Run("rm", "-f", app.BinaryPath)
+			}
+			binary.RemoveVersions(filepath.Base(app.BinaryPath))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove binary: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
-func installWithMethod(rel *github.Release, app *config.App, method binary.InstallMethod) error {
-	if method == binary.Homebrew {
-		if err := tryHomebrewInstall(app); err != nil {
-			app.InstallStatus = config.StatusFailed
-			app.InstallError = err.Error()
-			return err
-		}
-		app.InstallStatus = config.StatusInstalled
-		return nil
+// PlanInstall reports what InstallApp would do for app without downloading
+// or executing anything. A nil release resolves the app's release.
+func PlanInstall(rel *github.Release, app config.App, method binary.InstallMethod, s config.Settings) (*installer.Plan, error) {
+	return installer.New(installer.Request{App: app, Release: rel, Method: method, Settings: s}).Plan()
+}
+
+// PlanUninstall describes what UninstallApp would do for app.
+func PlanUninstall(app config.App) []string {
+	if app.Adopted {
+		return []string{"Leave the adopted install in place"}
 	}
-	
-	if err := tryBinaryInstall(rel, app, method); err != nil {
-		app.InstallStatus = config.StatusFailed
-		app.InstallError = err.Error()
-		return err
+	switch app.InstallMethod {
+	case config.InstallMethodHomebrew:
+		return []string{"Run: brew uninstall " + app.Name}
+	case config.InstallMethodBinary:
+		if app.BinaryPath == "" {
+			break
+		}
+		return []string{
+			"Remove " + app.BinaryPath,
+			"Remove kept versions in " + binary.VersionsDir(filepath.Base(app.BinaryPath)),
+		}
+	case config.InstallMethodPackage:
+		return []string{"Leave the system package installed"}
 	}
-	app.InstallStatus = config.StatusInstalled
 	return nil
 }
 
-func tryPackageInstall(rel *github.Release, app *config.App) error {
-	assets, err := installer.GetCompatibleAssets(rel)
-	if err != nil || len(assets) == 0 {
-		return fmt.Errorf("no compatible assets")
+// RemoveApp uninstalls app and stops tracking it. The app is untracked
+// even if uninstalling fails; that error is returned as a warning.
+func RemoveApp(app config.App, s config.Settings) error {
+	entry := history.Entry{
+		Action: history.ActionRemove,
+		App:    app.Name,
+		Repo:   app.RepoURL,
+		From:   app.Version,
+		Method: app.InstallMethod,
 	}
 
-	if _, err := installer.InstallUpdate(rel, &installer.InstallOptions{Method: binary.Auto}); err != nil {
+	uninstallErr := UninstallApp(app, s)
+	if _, err := config.Update(func(c *config.Config) error {
+		c.RemoveApp(app.RepoURL)
+		return nil
+	}); err != nil {
+		history.Append(entry, err)
 		return err
 	}
-
-	app.Version = strings.TrimPrefix(rel.TagName, "v")
-	app.InstallMethod = config.InstallMethodPackage
-	return nil
+	history.Append(entry, uninstallErr)
+	return uninstallErr
 }
 
-func tryHomebrewInstall(app *config.App) error {
-	if !homebrew.IsInstalled() {
-		return fmt.Errorf("homebrew not installed")
+// RollbackApp switches a binary install to a kept version, the previous
+// one when version is empty, and returns the version now active.
+func RollbackApp(app config.App, version string, s config.Settings) (string, error) {
+	if app.InstallMethod != config.InstallMethodBinary || app.BinaryPath == "" {
+		return "", fmt.Errorf("rollback is only supported for binary installs")
 	}
 
-	formula, err := homebrew.SearchFormula(app.Name)
-	if err != nil {
-		return err
+	entry := history.Entry{
+		Action: history.ActionRollback,
+		App:    app.Name,
+		Repo:   app.RepoURL,
+		From:   app.Version,
+		Method: app.InstallMethod,
+		Sudo:   binary.RequiresSudo(app.BinaryPath),
 	}
 
-	if err := homebrew.InstallOfficial(formula); err != nil {
-		return err
+	active, err := binary.Rollback(filepath.Base(app.BinaryPath), app.BinaryPath, version, binary.NewOptions(s).Escalator)
+	if err != nil {
+		history.Append(entry, err)
+		return "", err
 	}
+	entry.To = active
+
+	_, err = config.Update(func(c *config.Config) error {
+		if a := c.FindApp(app.RepoURL); a != nil {
+			a.Version = active
+			a.BinaryDigest, _ = installer.FileDigest(app.BinaryPath)
+			// The kept version's asset is not known
+			a.AssetDigest = ""
+		}
+		return nil
+	})
+	history.Append(entry, err)
+	return active, err
+}
 
-	if ver, err := homebrew.GetInstalledVersion(app.Name); err == nil {
-		app.Version = ver
+// NormalizeVersion strips a leading "v" and any Debian/RPM package revision
+// (e.g. "0.1.1-1" -> "0.1.1") so installed and release versions compare.
+func NormalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	// Remove "v" prefix
+	v = strings.TrimPrefix(v, "v")
+	// Remove Debian/RPM package revision suffix (e.g., "0.1.1-1" -> "0.1.1")
+	if idx := strings.LastIndex(v, "-"); idx > 0 {
+		// Only strip if what follows the dash looks like a package revision (number)
+		suffix := v[idx+1:]
+		// Check if suffix is purely numeric (Debian revision) or contains "el" (RPM dist tag)
+		if len(suffix) > 0 && (isNumeric(suffix) || strings.Contains(suffix, "el")) {
+			v = v[:idx]
+		}
 	}
-	app.InstallMethod = config.InstallMethodHomebrew
-	return nil
+	return v
 }
 
-func tryBinaryInstall(rel *github.Release, app *config.App, method binary.InstallMethod) error {
-	result, err := installer.InstallUpdate(rel, &installer.InstallOptions{Method: method})
-	if err != nil {
-		return err
+// compareVersions compares two installed or release versions as semantic
+// versions, returning -1, 0 or 1 as a is older than, equal to or newer
+// than b. ok is false when either does not parse.
+func compareVersions(a, b string) (int, bool) {
+	va, okA := semver.Parse(NormalizeVersion(a))
+	vb, okB := semver.Parse(NormalizeVersion(b))
+	if !okA || !okB {
+		return 0, false
 	}
+	return semver.Compare(va, vb), true
+}
 
-	app.Version = strings.TrimPrefix(rel.TagName, "v")
-	app.BinaryPath = result.Path
-	app.InstallMethod = config.InstallMethodBinary
-	return nil
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
 }
@@ -0,0 +1,215 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+	"github.com/tim/autonomix-cli/pkg/selfupdate"
+)
+
+// SyncAction is what a sync does to bring one app in line with a manifest.
+type SyncAction string
+
+const (
+	SyncAdd       SyncAction = "add"       // track and install
+	SyncInstall   SyncAction = "install"   // tracked but not installed
+	SyncUpgrade   SyncAction = "upgrade"   // installed version is older
+	SyncDowngrade SyncAction = "downgrade" // installed version is newer than the pin
+	SyncConfigure SyncAction = "configure" // only pin, channel or asset rules change
+	SyncRemove    SyncAction = "remove"    // tracked but not in the manifest
+	SyncKeep      SyncAction = "ok"
+)
+
+// SyncStep is one planned change. App holds the desired tracked state.
+type SyncStep struct {
+	Action  SyncAction
+	App     config.App
+	Method  binary.InstallMethod
+	From    string
+	To      string
+	Release *github.Release
+
+	// Err is set when the target release could not be resolved; the step
+	// is skipped.
+	Err error
+}
+
+// ResolveFunc finds the release an app should be on.
+type ResolveFunc func(config.App) (*github.Release, error)
+
+// PlanSync compares the tracked apps with m and returns the steps that
+// make them match. With prune, tracked apps missing from the manifest are
+// removed; autonomix-cli itself is never pruned. A nil resolve uses
+// LatestRelease with cfg's settings.
+func PlanSync(cfg *config.Config, m *manifest.Manifest, prune bool, resolve ResolveFunc) []SyncStep {
+	if resolve == nil {
+		resolve = func(app config.App) (*github.Release, error) { return LatestRelease(app, cfg.Settings) }
+	}
+
+	steps := make([]SyncStep, len(m.Apps))
+	var wg sync.WaitGroup
+	for i, entry := range m.Apps {
+		wg.Add(1)
+		go func(i int, entry manifest.App) {
+			defer wg.Done()
+			steps[i] = planApp(cfg, entry, resolve)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	if prune {
+		listed := make(map[string]bool)
+		for _, s := range steps {
+			listed[strings.ToLower(s.App.RepoURL)] = true
+		}
+		for _, app := range cfg.Apps {
+			if listed[strings.ToLower(app.RepoURL)] || strings.EqualFold(app.RepoURL, selfupdate.RepoURL) {
+				continue
+			}
+			steps = append(steps, SyncStep{Action: SyncRemove, App: app, From: app.Version})
+		}
+	}
+	return steps
+}
+
+func planApp(cfg *config.Config, entry manifest.App, resolve ResolveFunc) SyncStep {
+	repoURL := CleanRepoURL(manifest.RepoURL(entry.Repo))
+
+	step := SyncStep{Action: SyncKeep, Method: binary.Auto}
+	tracked := cfg.FindApp(repoURL)
+	if tracked != nil {
+		step.App = *tracked
+	} else {
+		step.Action = SyncAdd
+		step.App = config.App{RepoURL: repoURL}
+	}
+
+	// An unnamed new app gets its name from AddApp
+	desired := step.App
+	if entry.Name != "" {
+		desired.Name = entry.Name
+	}
+	// An unversioned entry leaves a pin set with `autonomix-cli set` alone
+	if entry.Version != "" {
+		desired.Pin = entry.Version
+	}
+	desired.Channel = entry.Channel
+	desired.AssetPattern = entry.AssetPattern
+	if tracked != nil && (desired.Name != tracked.Name || desired.Pin != tracked.Pin ||
+		desired.Channel != tracked.Channel || desired.AssetPattern != tracked.AssetPattern) {
+		step.Action = SyncConfigure
+	}
+
+	// The manifest's method only applies to fresh installs; updates stay
+	// on whatever method the app was installed with
+	if desired.Version == "" {
+		step.Method = applyMethod(&desired, entry.Method)
+	}
+	step.App = desired
+	step.From = desired.Version
+
+	rel, err := resolve(desired)
+	if err != nil {
+		step.Err = fmt.Errorf("failed to resolve release: %w", err)
+		return step
+	}
+	step.Release = rel
+	step.To = rel.TagName
+
+	newer, comparable := compareVersions(desired.Version, rel.TagName)
+	switch {
+	case desired.Version == "":
+		if step.Action != SyncAdd {
+			step.Action = SyncInstall
+		}
+	case sameVersion(desired.Version, rel.TagName):
+		// Already on the target release
+	case comparable && newer > 0 && desired.Pin != "":
+		step.Action = SyncDowngrade
+	case comparable && newer > 0:
+		// Installed ahead of the channel's latest release; only a pin
+		// moves an app back
+	default:
+		step.Action = SyncUpgrade
+	}
+	return step
+}
+
+// applyMethod sets up app for a fresh install with a manifest install
+// method and returns the method to pass to the installer.
+func applyMethod(app *config.App, method string) binary.InstallMethod {
+	switch method {
+	case manifest.MethodPackage, manifest.MethodBinary:
+		app.InstallMethod = method
+	case manifest.MethodHomebrew:
+		return binary.Homebrew
+	case manifest.MethodSystem:
+		app.InstallMethod = config.InstallMethodBinary
+		return binary.SystemPath
+	}
+	return binary.Auto
+}
+
+// ApplySync carries out step and returns the resulting tracked app, which
+// is nil after a removal.
+func ApplySync(step SyncStep, s config.Settings) (*config.App, error) {
+	if step.Err != nil {
+		return nil, step.Err
+	}
+
+	switch step.Action {
+	case SyncKeep:
+		return &step.App, nil
+	case SyncRemove:
+		return nil, RemoveApp(step.App, s)
+	case SyncAdd:
+		res, err := AddApp(step.App.RepoURL, s)
+		if err != nil {
+			return nil, err
+		}
+		step.App.Version = res.App.Version
+		if err := configure(step.App); err != nil {
+			return nil, err
+		}
+		if v := res.App.Version; v != "" {
+			newer, comparable := compareVersions(v, step.To)
+			if sameVersion(v, step.To) || (comparable && newer > 0 && step.App.Pin == "") {
+				return &res.App, nil
+			}
+		}
+	default:
+		if err := configure(step.App); err != nil {
+			return nil, err
+		}
+		if step.Action == SyncConfigure {
+			return &step.App, nil
+		}
+	}
+
+	return InstallApp(step.Release, step.App, step.Method, s)
+}
+
+// configure stores the manifest-controlled fields of app.
+func configure(app config.App) error {
+	_, err := config.Update(func(c *config.Config) error {
+		tracked := c.FindApp(app.RepoURL)
+		if tracked == nil {
+			return fmt.Errorf("%s is no longer tracked", app.RepoURL)
+		}
+		if app.Name != "" {
+			tracked.Name = app.Name
+		}
+		if app.Pin != "" {
+			tracked.Pin = app.Pin
+		}
+		tracked.Channel = app.Channel
+		tracked.AssetPattern = app.AssetPattern
+		return nil
+	})
+	return err
+}
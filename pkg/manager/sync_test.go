@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/manifest"
+)
+
+func TestPlanSync(t *testing.T) {
+	cfg := &config.Config{Apps: []config.App{
+		{Name: "rg", RepoURL: "https://github.com/BurntSushi/ripgrep", Version: "14.1.1"},
+		{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", Version: "0.50.0"},
+		{Name: "bat", RepoURL: "https://github.com/sharkdp/bat", Version: "v0.24.0"},
+		{Name: "jq", RepoURL: "https://github.com/jqlang/jq", Version: "1.7"},
+		{Name: "jq", RepoURL: "https://github.com/stedolan/jq", Version: "1.7.1"},
+		{Name: "delta", RepoURL: "https://github.com/dandavison/delta", Version: "0.18.2"},
+	}}
+	m := &manifest.Manifest{Apps: []manifest.App{
+		{Repo: "BurntSushi/ripgrep", Version: "14.1.0"},
+		{Repo: "junegunn/fzf"},
+		{Repo: "sharkdp/bat"},
+		{Repo: "cli/cli"},
+		{Repo: "broken/repo"},
+		{Repo: "stedolan/jq"},
+		{Repo: "dandavison/delta"},
+	}}
+
+	latest := map[string]string{
+		"https://github.com/junegunn/fzf": "v0.55.0",
+		"https://github.com/sharkdp/bat":  "v0.24.0",
+		"https://github.com/cli/cli":      "v2.60.0",
+		"https://github.com/stedolan/jq":  "jq-1.7.1",
+		// Older than what is installed
+		"https://github.com/dandavison/delta": "0.18.1",
+	}
+	resolve := func(app config.App) (*github.Release, error) {
+		if app.Pin != "" {
+			return &github.Release{TagName: app.Pin}, nil
+		}
+		tag, ok := latest[app.RepoURL]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return &github.Release{TagName: tag}, nil
+	}
+
+	steps := PlanSync(cfg, m, true, resolve)
+
+	want := map[string]SyncAction{
+		"https://github.com/BurntSushi/ripgrep": SyncDowngrade,
+		"https://github.com/junegunn/fzf":       SyncUpgrade,
+		"https://github.com/sharkdp/bat":        SyncKeep,
+		"https://github.com/cli/cli":            SyncAdd,
+		"https://github.com/jqlang/jq":          SyncRemove,
+		"https://github.com/stedolan/jq":        SyncKeep,
+		"https://github.com/dandavison/delta":   SyncKeep,
+	}
+	got := make(map[string]SyncStep)
+	for _, s := range steps {
+		got[s.App.RepoURL] = s
+	}
+	for repo, action := range want {
+		if got[repo].Action != action {
+			t.Errorf("%s: action %s, want %s", repo, got[repo].Action, action)
+		}
+	}
+	if got["https://github.com/broken/repo"].Err == nil {
+		t.Error("expected resolve error for broken/repo")
+	}
+	if rg := got["https://github.com/BurntSushi/ripgrep"]; rg.App.Pin != "14.1.0" || rg.To != "14.1.0" {
+		t.Errorf("ripgrep step not pinned: %+v", rg)
+	}
+}
+
+func TestPlanSyncConfigureOnly(t *testing.T) {
+	cfg := &config.Config{Apps: []config.App{
+		{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", Version: "0.55.0"},
+	}}
+	m := &manifest.Manifest{Apps: []manifest.App{
+		{Repo: "junegunn/fzf", AssetPattern: "fzf-{version}-{os}_{arch}.tar.gz"},
+	}}
+	resolve := func(config.App) (*github.Release, error) {
+		return &github.Release{TagName: "v0.55.0"}, nil
+	}
+
+	steps := PlanSync(cfg, m, false, resolve)
+	if len(steps) != 1 || steps[0].Action != SyncConfigure {
+		t.Fatalf("unexpected plan %+v", steps)
+	}
+}
+
+func TestPlanSyncKeepsUserPin(t *testing.T) {
+	cfg := &config.Config{Apps: []config.App{
+		{Name: "fzf", RepoURL: "https://github.com/junegunn/fzf", Version: "0.50.0", Pin: "0.50.0"},
+	}}
+	m := &manifest.Manifest{Apps: []manifest.App{{Repo: "junegunn/fzf"}}}
+	resolve := func(app config.App) (*github.Release, error) {
+		if app.Pin != "" {
+			return &github.Release{TagName: app.Pin}, nil
+		}
+		return &github.Release{TagName: "v0.55.0"}, nil
+	}
+
+	steps := PlanSync(cfg, m, false, resolve)
+	if len(steps) != 1 || steps[0].Action != SyncKeep || steps[0].App.Pin != "0.50.0" {
+		t.Fatalf("unversioned entry changed the pin: %+v", steps)
+	}
+}
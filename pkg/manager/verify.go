@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/history"
+	"github.com/tim/autonomix-cli/pkg/homebrew"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// Drift kinds reported by VerifyApp
+const (
+	DriftMissing  = "missing"  // nothing is installed where the config says
+	DriftVersion  = "version"  // a different version is installed
+	DriftModified = "modified" // the binary differs from the one installed
+)
+
+// Drift is one way an app's config disagrees with the system.
+type Drift struct {
+	Kind   string
+	Detail string
+}
+
+// Verification is what VerifyApp found on the system for an app.
+type Verification struct {
+	App     config.App
+	Version string // Installed version found; empty when unknown
+	Digest  string // SHA-256 of the binary, for binary installs
+	Drift   []Drift
+}
+
+// Installed reports whether the config records an install to verify.
+func Installed(app config.App) bool {
+	return app.Version != "" && app.InstallStatus != config.StatusFailed
+}
+
+// VerifyApp re-checks an installed app through the method it was installed
+// with: the binary's digest and --version output, Homebrew, or the system
+// package managers.
+func VerifyApp(app config.App) Verification {
+	v := Verification{App: app}
+	if !Installed(app) {
+		return v
+	}
+
+	switch app.InstallMethod {
+	case config.InstallMethodBinary:
+		v.verifyBinary()
+	case config.InstallMethodHomebrew:
+		ver, err := homebrew.GetInstalledVersion(app.Name)
+		if err != nil {
+			v.drift(DriftMissing, "not installed via Homebrew")
+			return v
+		}
+		v.compareVersion(system.ExtractVersion(ver, nil))
+	default:
+		ver, typ, ok := system.CheckInstalled(app.Name)
+		if !ok || (app.InstallMethod == config.InstallMethodPackage && typ == packages.Unknown) {
+			v.drift(DriftMissing, "no installed package found")
+			return v
+		}
+		v.compareVersion(system.ExtractVersion(ver, nil))
+	}
+	return v
+}
+
+func (v *Verification) verifyBinary() {
+	path := v.App.BinaryPath
+	if path == "" {
+		v.drift(DriftMissing, "no binary path recorded")
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.drift(DriftMissing, path+" does not exist")
+		return
+	}
+
+	digest, err := installer.FileDigest(path)
+	if err == nil {
+		v.Digest = digest
+		if v.App.BinaryDigest != "" && digest != v.App.BinaryDigest {
+			v.drift(DriftModified, path+" changed since it was installed")
+		}
+	}
+	if res, err := system.ProbeVersion(path, VersionProbe(v.App)); err == nil {
+		v.compareVersion(res.Version)
+	}
+}
+
+// compareVersion records the version found and a drift when it differs
+// from the configured one. An unknown version is not treated as drift.
+func (v *Verification) compareVersion(found string) {
+	if found == "" {
+		return
+	}
+	v.Version = found
+	if !sameVersion(found, v.App.Version) {
+		v.drift(DriftVersion, fmt.Sprintf("%s is installed, config records %s", found, v.App.Version))
+	}
+}
+
+func (v *Verification) drift(kind, detail string) {
+	v.Drift = append(v.Drift, Drift{Kind: kind, Detail: detail})
+}
+
+// sameVersion compares versions semantically when both parse, so "1.7.1"
+// matches a tag like "jq-1.7.1".
+func sameVersion(a, b string) bool {
+	if c, ok := compareVersions(a, b); ok {
+		return c == 0
+	}
+	return NormalizeVersion(a) == NormalizeVersion(b)
+}
+
+// VersionProbe returns how app's binary is asked for its version.
+func VersionProbe(app config.App) system.VersionProbe {
+	probe := system.VersionProbe{Pattern: app.VersionPattern}
+	if len(app.VersionArgs) > 0 {
+		probe.Args = [][]string{app.VersionArgs}
+	}
+	return probe
+}
+
+// FixDrift updates the config to match what VerifyApp found: a missing
+// install is marked not installed, otherwise the found version and binary
+// digest are recorded. The change is written to the history.
+func FixDrift(v Verification) (*config.App, error) {
+	if len(v.Drift) == 0 {
+		return &v.App, nil
+	}
+
+	missing := false
+	for _, d := range v.Drift {
+		if d.Kind == DriftMissing {
+			missing = true
+		}
+	}
+
+	entry := history.Entry{
+		Action: history.ActionFix,
+		App:    v.App.Name,
+		Repo:   v.App.RepoURL,
+		From:   v.App.Version,
+		Method: v.App.InstallMethod,
+	}
+	var updated config.App
+	_, err := config.Update(func(c *config.Config) error {
+		app := c.FindApp(v.App.RepoURL)
+		if app == nil {
+			return fmt.Errorf("%s is no longer tracked", v.App.RepoURL)
+		}
+		if missing {
+			app.Version = ""
+			app.InstallStatus = ""
+			app.BinaryPath = ""
+			app.BinaryDigest = ""
+			app.AssetDigest = ""
+		} else {
+			if v.Version != "" {
+				app.Version = v.Version
+			}
+			if v.Digest != "" {
+				app.BinaryDigest = v.Digest
+			}
+		}
+		updated = *app
+		return nil
+	})
+	entry.To = updated.Version
+	history.Append(entry, err)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
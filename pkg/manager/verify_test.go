@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/history"
+	"github.com/tim/autonomix-cli/pkg/installer"
+)
+
+// writeTool writes a script printing "tool <version>" for --version.
+func writeTool(t *testing.T, path, version string) {
+	t.Helper()
+	script := "#!/bin/sh\necho \"tool " + version + " (rev abc)\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyApp(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	path := filepath.Join(t.TempDir(), "tool")
+	writeTool(t, path, "1.2.3")
+	digest, err := installer.FileDigest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := config.App{
+		Name:          "tool",
+		RepoURL:       "https://github.com/o/tool",
+		Version:       "1.2.3",
+		InstallMethod: config.InstallMethodBinary,
+		InstallStatus: config.StatusInstalled,
+		BinaryPath:    path,
+		BinaryDigest:  digest,
+	}
+
+	if v := VerifyApp(app); len(v.Drift) != 0 || v.Version != "1.2.3" {
+		t.Fatalf("unchanged install: got %+v", v)
+	}
+
+	// Upgraded outside autonomix
+	writeTool(t, path, "1.3.0")
+	v := VerifyApp(app)
+	if !hasDrift(v, DriftModified) || !hasDrift(v, DriftVersion) || v.Version != "1.3.0" {
+		t.Fatalf("upgraded install: got %+v", v)
+	}
+
+	os.Remove(path)
+	if v := VerifyApp(app); !hasDrift(v, DriftMissing) {
+		t.Fatalf("deleted install: got %+v", v)
+	}
+}
+
+func TestFixDrift(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	path := filepath.Join(t.TempDir(), "tool")
+	writeTool(t, path, "1.3.0")
+	app := config.App{
+		Name:          "tool",
+		RepoURL:       "https://github.com/o/tool",
+		Version:       "1.2.3",
+		InstallMethod: config.InstallMethodBinary,
+		InstallStatus: config.StatusInstalled,
+		BinaryPath:    path,
+	}
+	if _, err := config.Update(func(c *config.Config) error {
+		c.Apps = []config.App{app}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := FixDrift(VerifyApp(app))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != "1.3.0" || updated.BinaryDigest == "" {
+		t.Errorf("after fix: got version %q, digest %q", updated.Version, updated.BinaryDigest)
+	}
+
+	os.Remove(path)
+	updated, err = FixDrift(VerifyApp(*updated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != "" || updated.BinaryPath != "" || Installed(*updated) {
+		t.Errorf("after fixing a missing binary: got %+v", updated)
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Action != history.ActionFix || entries[0].To != "1.3.0" {
+		t.Errorf("history = %+v", entries)
+	}
+}
+
+func hasDrift(v Verification, kind string) bool {
+	for _, d := range v.Drift {
+		if d.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVerifyAppVersionArgs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool")
+	script := "#!/bin/sh\n[ \"$1\" = \"about\" ] && echo \"tool release 7.2\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	app := config.App{
+		Name:           "tool",
+		Version:        "7.1",
+		InstallMethod:  config.InstallMethodBinary,
+		InstallStatus:  config.StatusInstalled,
+		BinaryPath:     path,
+		VersionArgs:    []string{"about"},
+		VersionPattern: `release (\S+)`,
+	}
+	if v := VerifyApp(app); v.Version != "7.2" || !hasDrift(v, DriftVersion) {
+		t.Errorf("VerifyApp = %+v, want 7.2 read with the app's probe", v)
+	}
+}
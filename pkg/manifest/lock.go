@@ -0,0 +1,200 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// LockName is the lockfile written next to a manifest.
+const LockName = "autonomix.lock"
+
+// LockVersion is the lockfile format version.
+const LockVersion = 1
+
+// Lock records exactly what a manifest resolved to, so every machine
+// installs byte-identical assets.
+type Lock struct {
+	Version int         `json:"version"`
+	Apps    []LockedApp `json:"apps"`
+}
+
+// LockedApp is the resolved state of one manifest entry. The manifest
+// fields are kept so a changed entry can be detected; assets are recorded
+// per platform because each OS and architecture gets its own.
+type LockedApp struct {
+	Repo         string        `json:"repo"`
+	Version      string        `json:"version,omitempty"`
+	Channel      string        `json:"channel,omitempty"`
+	AssetPattern string        `json:"asset_pattern,omitempty"`
+	Method       string        `json:"method,omitempty"`
+	Tag          string        `json:"tag"`
+	Assets       []LockedAsset `json:"assets"`
+}
+
+// LockedAsset is the release asset installed on one platform.
+type LockedAsset struct {
+	Platform string `json:"platform"` // GOOS/GOARCH
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	SHA256   string `json:"sha256"`
+}
+
+// Platform returns the lock platform key of the running system.
+func Platform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// LockPath returns the lockfile belonging to the manifest at path.
+func LockPath(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), LockName)
+}
+
+// LoadLock reads a lockfile. A missing file is reported as an error
+// wrapping os.ErrNotExist.
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if l.Version > LockVersion {
+		return nil, fmt.Errorf("%s: lockfile version %d is newer than supported version %d", path, l.Version, LockVersion)
+	}
+	return &l, nil
+}
+
+// Save writes the lock sorted by repo and platform, so regenerating an
+// unchanged lock yields an identical file.
+func (l *Lock) Save(path string) error {
+	l.Version = LockVersion
+	sort.Slice(l.Apps, func(i, j int) bool {
+		return strings.ToLower(l.Apps[i].Repo) < strings.ToLower(l.Apps[j].Repo)
+	})
+	for _, app := range l.Apps {
+		sort.Slice(app.Assets, func(i, j int) bool { return app.Assets[i].Platform < app.Assets[j].Platform })
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Find returns the record for the manifest entry's repo, or nil.
+func (l *Lock) Find(entry App) *LockedApp {
+	key := strings.ToLower(RepoURL(entry.Repo))
+	for i := range l.Apps {
+		if strings.ToLower(RepoURL(l.Apps[i].Repo)) == key {
+			return &l.Apps[i]
+		}
+	}
+	return nil
+}
+
+// Put stores app. When the existing record locks the same entry to the
+// same tag, assets for other platforms are kept.
+func (l *Lock) Put(app LockedApp) {
+	existing := l.Find(App{Repo: app.Repo})
+	if existing == nil {
+		l.Apps = append(l.Apps, app)
+		return
+	}
+
+	if existing.Tag == app.Tag && existing.Matches(app.entry()) {
+		for _, asset := range existing.Assets {
+			if app.Asset(asset.Platform) == nil {
+				app.Assets = append(app.Assets, asset)
+			}
+		}
+	}
+	*existing = app
+}
+
+// Unlisted returns the repos locked but no longer in m.
+func (l *Lock) Unlisted(m *Manifest) []string {
+	listed := make(map[string]bool)
+	for _, entry := range m.Apps {
+		listed[strings.ToLower(RepoURL(entry.Repo))] = true
+	}
+
+	var repos []string
+	for _, app := range l.Apps {
+		if !listed[strings.ToLower(RepoURL(app.Repo))] {
+			repos = append(repos, app.Repo)
+		}
+	}
+	return repos
+}
+
+// Remove drops the record for repo.
+func (l *Lock) Remove(repo string) {
+	key := strings.ToLower(RepoURL(repo))
+	apps := l.Apps[:0]
+	for _, app := range l.Apps {
+		if strings.ToLower(RepoURL(app.Repo)) != key {
+			apps = append(apps, app)
+		}
+	}
+	l.Apps = apps
+}
+
+// Outdated returns the entries of m the lock has no current record for on
+// platform: missing apps, changed entries and platforms never locked.
+func (l *Lock) Outdated(m *Manifest, platform string) []App {
+	var outdated []App
+	for _, entry := range m.Apps {
+		locked := l.Find(entry)
+		if locked == nil || !locked.Matches(entry) || locked.Asset(platform) == nil {
+			outdated = append(outdated, entry)
+		}
+	}
+	return outdated
+}
+
+// Matches reports whether the record was made from entry as it is now.
+func (a *LockedApp) Matches(entry App) bool {
+	return strings.EqualFold(RepoURL(a.Repo), RepoURL(entry.Repo)) &&
+		a.Version == entry.Version &&
+		a.Channel == entry.Channel &&
+		a.AssetPattern == entry.AssetPattern &&
+		a.Method == entry.Method
+}
+
+// Asset returns the asset locked for platform, or nil.
+func (a *LockedApp) Asset(platform string) *LockedAsset {
+	for i := range a.Assets {
+		if a.Assets[i].Platform == platform {
+			return &a.Assets[i]
+		}
+	}
+	return nil
+}
+
+func (a *LockedApp) entry() App {
+	return App{
+		Repo:         a.Repo,
+		Version:      a.Version,
+		Channel:      a.Channel,
+		AssetPattern: a.AssetPattern,
+		Method:       a.Method,
+	}
+}
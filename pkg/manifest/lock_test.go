@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockPut(t *testing.T) {
+	linux := LockedAsset{Platform: "linux/amd64", Name: "rg-linux.tar.gz", URL: "https://example.com/rg-linux.tar.gz", SHA256: "aa"}
+	darwin := LockedAsset{Platform: "darwin/arm64", Name: "rg-darwin.tar.gz", URL: "https://example.com/rg-darwin.tar.gz", SHA256: "bb"}
+
+	l := &Lock{}
+	l.Put(LockedApp{Repo: "BurntSushi/ripgrep", Tag: "14.1.0", Assets: []LockedAsset{linux}})
+
+	// Same tag from another platform adds its asset
+	l.Put(LockedApp{Repo: "https://github.com/BurntSushi/ripgrep", Tag: "14.1.0", Assets: []LockedAsset{darwin}})
+	if len(l.Apps) != 1 || len(l.Apps[0].Assets) != 2 {
+		t.Fatalf("expected one app with two assets, got %+v", l.Apps)
+	}
+
+	// A new tag replaces every platform
+	l.Put(LockedApp{Repo: "BurntSushi/ripgrep", Tag: "14.1.1", Assets: []LockedAsset{linux}})
+	if len(l.Apps) != 1 || len(l.Apps[0].Assets) != 1 || l.Apps[0].Tag != "14.1.1" {
+		t.Fatalf("expected the record to be replaced, got %+v", l.Apps)
+	}
+}
+
+func TestLockOutdated(t *testing.T) {
+	asset := LockedAsset{Platform: "linux/amd64", Name: "a", URL: "u", SHA256: "s"}
+	l := &Lock{Apps: []LockedApp{
+		{Repo: "BurntSushi/ripgrep", Version: "14.1.0", Tag: "14.1.0", Assets: []LockedAsset{asset}},
+		{Repo: "junegunn/fzf", Tag: "v0.55.0", Assets: []LockedAsset{asset}},
+		{Repo: "sharkdp/bat", Tag: "v0.24.0", Assets: []LockedAsset{{Platform: "darwin/arm64"}}},
+		{Repo: "jqlang/jq", Tag: "jq-1.7.1", Assets: []LockedAsset{asset}},
+	}}
+	m := &Manifest{Apps: []App{
+		{Repo: "BurntSushi/ripgrep", Version: "14.1.0"},
+		{Repo: "junegunn/fzf", Channel: "prerelease"},
+		{Repo: "sharkdp/bat"},
+		{Repo: "cli/cli"},
+	}}
+
+	var got []string
+	for _, entry := range l.Outdated(m, "linux/amd64") {
+		got = append(got, entry.Repo)
+	}
+	want := []string{"junegunn/fzf", "sharkdp/bat", "cli/cli"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outdated = %v, want %v", got, want)
+	}
+
+	if unlisted := l.Unlisted(m); !reflect.DeepEqual(unlisted, []string{"jqlang/jq"}) {
+		t.Errorf("unlisted = %v", unlisted)
+	}
+}
+
+func TestLockSaveLoad(t *testing.T) {
+	path := LockPath(filepath.Join(t.TempDir(), "autonomix.yaml"))
+	if _, err := LoadLock(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a missing lockfile error, got %v", err)
+	}
+
+	l := &Lock{Apps: []LockedApp{
+		{Repo: "junegunn/fzf", Tag: "v0.55.0", Assets: []LockedAsset{{Platform: "linux/amd64"}, {Platform: "darwin/arm64"}}},
+		{Repo: "BurntSushi/ripgrep", Tag: "14.1.0", Assets: []LockedAsset{{Platform: "linux/amd64"}}},
+	}}
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := os.ReadFile(path)
+
+	loaded, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != LockVersion || loaded.Apps[0].Repo != "BurntSushi/ripgrep" || loaded.Apps[1].Assets[0].Platform != "darwin/arm64" {
+		t.Errorf("lock not sorted on save: %+v", loaded)
+	}
+
+	if err := loaded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := os.ReadFile(path); string(second) != string(first) {
+		t.Errorf("saving an unchanged lock changed the file:\n%s\n%s", first, second)
+	}
+}
@@ -0,0 +1,179 @@
+// Package manifest reads the autonomix.yaml / autonomix.toml files teams
+// use to declare which apps every machine should have.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/installer"
+)
+
+// Install methods accepted in a manifest
+const (
+	MethodAuto     = "auto"
+	MethodPackage  = config.InstallMethodPackage
+	MethodHomebrew = config.InstallMethodHomebrew
+	MethodBinary   = config.InstallMethodBinary
+	MethodSystem   = "system" // binary into /usr/local/bin
+)
+
+// App is one manifest entry. Only Repo is required.
+type App struct {
+	Repo         string `yaml:"repo" toml:"repo"`
+	Name         string `yaml:"name,omitempty" toml:"name,omitempty"`
+	Version      string `yaml:"version,omitempty" toml:"version,omitempty"` // pinned release tag
+	Channel      string `yaml:"channel,omitempty" toml:"channel,omitempty"`
+	AssetPattern string `yaml:"asset_pattern,omitempty" toml:"asset_pattern,omitempty"`
+	Method       string `yaml:"method,omitempty" toml:"method,omitempty"`
+}
+
+// Manifest is a declared set of apps.
+type Manifest struct {
+	Apps []App `yaml:"apps" toml:"apps"`
+}
+
+// Load reads a manifest, choosing the format by file extension. Files
+// without a .toml extension are read as YAML.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := Parse(data, FormatFor(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// FormatFor returns the manifest format for path: "toml" for .toml files
+// and "yaml" for anything else.
+func FormatFor(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return "toml"
+	}
+	return "yaml"
+}
+
+// Parse decodes manifest data in the given format, "yaml" or "toml", and
+// validates it.
+func Parse(data []byte, format string) (*Manifest, error) {
+	var m Manifest
+	switch format {
+	case "toml":
+		md, err := toml.Decode(string(data), &m)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("unknown field %s", undecoded[0])
+		}
+	case "yaml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", format)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Encode writes m in the given format, "yaml" or "toml".
+func (m *Manifest) Encode(format string) ([]byte, error) {
+	switch format {
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "yaml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported manifest format %q", format)
+}
+
+// Validate checks every entry, reporting all problems at once.
+func (m *Manifest) Validate() error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, app := range m.Apps {
+		label := fmt.Sprintf("app #%d", i+1)
+		if app.Repo == "" {
+			errs = append(errs, fmt.Errorf("%s: missing repo", label))
+			continue
+		}
+		label = app.Repo
+
+		key := strings.ToLower(RepoURL(app.Repo))
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: listed more than once", label))
+		}
+		seen[key] = true
+
+		switch app.Channel {
+		case "", config.ChannelStable, config.ChannelPrerelease:
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown channel %q", label, app.Channel))
+		}
+		switch app.Method {
+		case "", MethodAuto, MethodPackage, MethodHomebrew, MethodBinary, MethodSystem:
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown install method %q", label, app.Method))
+		}
+		if app.AssetPattern != "" {
+			if _, err := installer.CompilePattern(app.AssetPattern, app.Version); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", label, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ShortRepo reduces a GitHub URL to "owner/repo"; other URLs are returned
+// unchanged.
+func ShortRepo(repoURL string) string {
+	const prefix = "https://github.com/"
+	if strings.HasPrefix(repoURL, prefix) {
+		short := strings.Trim(strings.TrimPrefix(repoURL, prefix), "/")
+		if strings.Count(short, "/") == 1 {
+			return short
+		}
+	}
+	return repoURL
+}
+
+// RepoURL expands "owner/repo" shorthand to a GitHub URL.
+func RepoURL(repo string) string {
+	repo = strings.TrimSpace(repo)
+	if !strings.Contains(repo, "github.com/") && strings.Count(strings.Trim(repo, "/"), "/") == 1 {
+		return "https://github.com/" + strings.Trim(repo, "/")
+	}
+	return repo
+}
@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const yamlManifest = `
+apps:
+  - repo: BurntSushi/ripgrep
+    version: 14.1.0
+    asset_pattern: "ripgrep-{version}-{arch}-unknown-{os}-musl.tar.gz"
+  - repo: https://github.com/junegunn/fzf
+    channel: prerelease
+    method: binary
+`
+
+const tomlManifest = `
+[[apps]]
+repo = "BurntSushi/ripgrep"
+version = "14.1.0"
+
+[[apps]]
+repo = "https://github.com/junegunn/fzf"
+channel = "prerelease"
+method = "binary"
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"autonomix.yaml": yamlManifest, "autonomix.toml": tomlManifest} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(m.Apps) != 2 {
+			t.Fatalf("%s: got %d apps, want 2", name, len(m.Apps))
+		}
+		if m.Apps[0].Version != "14.1.0" || m.Apps[1].Channel != "prerelease" || m.Apps[1].Method != "binary" {
+			t.Errorf("%s: unexpected apps %+v", name, m.Apps)
+		}
+		if got := RepoURL(m.Apps[0].Repo); got != "https://github.com/BurntSushi/ripgrep" {
+			t.Errorf("RepoURL = %s", got)
+		}
+	}
+}
+
+func TestParseRejectsInvalidEntries(t *testing.T) {
+	tests := map[string]string{
+		"missing repo":   "apps:\n  - version: 1.0.0\n",
+		"bad channel":    "apps:\n  - repo: a/b\n    channel: nightly\n",
+		"bad method":     "apps:\n  - repo: a/b\n    method: snap\n",
+		"duplicate":      "apps:\n  - repo: a/b\n  - repo: https://github.com/A/B\n",
+		"unknown field":  "apps:\n  - repo: a/b\n    pinned: 1.0\n",
+		"bad re pattern": "apps:\n  - repo: a/b\n    asset_pattern: 're:('\n",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data), "yaml"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	m := &Manifest{Apps: []App{
+		{Repo: "BurntSushi/ripgrep", Name: "rg", Version: "14.1.0", AssetPattern: "re:musl"},
+		{Repo: "junegunn/fzf", Channel: "prerelease"},
+	}}
+	for _, format := range []string{"yaml", "toml"} {
+		data, err := m.Encode(format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		parsed, err := Parse(data, format)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(parsed, m) {
+			t.Errorf("%s: round trip gave %+v, want %+v", format, parsed, m)
+		}
+	}
+}
+
+func TestShortRepo(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://github.com/BurntSushi/ripgrep": "BurntSushi/ripgrep",
+		"https://gitlab.com/owner/repo":         "https://gitlab.com/owner/repo",
+	} {
+		if got := ShortRepo(in); got != want {
+			t.Errorf("ShortRepo(%q) = %q, want %q", in, got, want)
+		}
+		if RepoURL(ShortRepo(in)) != in {
+			t.Errorf("RepoURL(ShortRepo(%q)) does not round trip", in)
+		}
+	}
+}
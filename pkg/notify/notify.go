@@ -0,0 +1,37 @@
+// Package notify sends desktop notifications.
+package notify
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// Notifier shows a notification to the user.
+type Notifier interface {
+	Notify(summary, body string) error
+}
+
+// DBus sends freedesktop notifications over the session bus.
+type DBus struct {
+	AppName string
+}
+
+// Notify implements Notifier.
+func (d DBus) Notify(summary, body string) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	return obj.Call("org.freedesktop.Notifications.Notify", 0,
+		d.AppName,                 // app_name
+		uint32(0),                 // replaces_id
+		"",                        // app_icon
+		summary,                   // summary
+		body,                      // body
+		[]string{},                // actions
+		map[string]dbus.Variant{}, // hints
+		int32(-1),                 // expire_timeout: server default
+	).Err
+}
@@ -0,0 +1,136 @@
+// Package privilege runs commands that need root through the escalation
+// tool the user configured: sudo, doas, pkexec or run0.
+package privilege
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Escalation tools
+const (
+	Sudo   = "sudo"
+	Doas   = "doas"
+	Pkexec = "pkexec"
+	Run0   = "run0"
+
+	// Auto picks the first supported tool installed
+	Auto = "auto"
+)
+
+// Tools lists the supported tools in the order Auto looks for them.
+var Tools = []string{Sudo, Doas, Run0, Pkexec}
+
+var nonInteractive bool
+
+// Escalator runs commands as root through Tool, normally the
+// escalation-tool setting. An empty Tool or Auto picks the first supported
+// tool installed.
+type Escalator struct {
+	Tool string
+}
+
+// SetNonInteractive makes escalation fail instead of asking for a password,
+// for scripts and timers.
+func SetNonInteractive(v bool) {
+	nonInteractive = v
+}
+
+// NonInteractive reports whether escalation may prompt.
+func NonInteractive() bool {
+	return nonInteractive
+}
+
+// IsRoot reports whether the process already runs as root, in which case
+// nothing is escalated.
+func IsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// Resolve returns the configured escalation tool, or with the auto setting
+// the first supported tool installed.
+func (e Escalator) Resolve() (string, error) {
+	if e.Tool != "" && e.Tool != Auto {
+		if _, err := exec.LookPath(e.Tool); err != nil {
+			return "", fmt.Errorf("escalation tool %s not found", e.Tool)
+		}
+		return e.Tool, nil
+	}
+	for _, t := range Tools {
+		if _, err := exec.LookPath(t); err == nil {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("no escalation tool found (tried %s); run as root or install one", strings.Join(Tools, ", "))
+}
+
+// Command returns a command running name with args as root. It runs name
+// directly when already root. Stdin, Stdout and Stderr are left for the
+// caller to set.
+func (e Escalator) Command(name string, args ...string) (*exec.Cmd, error) {
+	if IsRoot() {
+		return exec.Command(name, args...), nil
+	}
+	tool, err := e.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	argv := Wrap(tool, nonInteractive, name, args...)
+	return exec.Command(argv[0], argv[1:]...), nil
+}
+
+// Wrap returns the argv running name with args through tool. When
+// nonInteractive is set the tool is told to fail rather than prompt.
+func Wrap(tool string, nonInteractive bool, name string, args ...string) []string {
+	argv := []string{tool}
+	if nonInteractive {
+		switch tool {
+		case Sudo, Doas:
+			argv = append(argv, "-n")
+		case Run0:
+			argv = append(argv, "--no-ask-password")
+		case Pkexec:
+			// Without its terminal agent pkexec fails when no
+			// authentication agent is running
+			argv = append(argv, "--disable-internal-agent")
+		}
+	}
+	if tool == Sudo || tool == Doas {
+		// End option parsing so name is never read as a flag
+		argv = append(argv, "--")
+	}
+	return append(append(argv, name), args...)
+}
+
+// Run runs name with args as root, showing its output. The terminal is
+// attached to stdin only when prompting is allowed, so a script never
+// hangs waiting for a password.
+func (e Escalator) Run(name string, args ...string) error {
+	cmd, err := e.Command(name, args...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if !nonInteractive {
+		cmd.Stdin = os.Stdin
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(cmd.Args, " "), err)
+	}
+	return nil
+}
+
+// RunQuiet is Run without output, for cleanup steps.
+func (e Escalator) RunQuiet(name string, args ...string) error {
+	cmd, err := e.Command(name, args...)
+	if err != nil {
+		return err
+	}
+	if !nonInteractive {
+		cmd.Stdin = os.Stdin
+	}
+	return cmd.Run()
+}
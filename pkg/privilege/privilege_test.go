@@ -0,0 +1,28 @@
+package privilege
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		tool           string
+		nonInteractive bool
+		want           []string
+	}{
+		{Sudo, false, []string{"sudo", "--", "rm", "-f", "/usr/local/bin/x"}},
+		{Sudo, true, []string{"sudo", "-n", "--", "rm", "-f", "/usr/local/bin/x"}},
+		{Doas, true, []string{"doas", "-n", "--", "rm", "-f", "/usr/local/bin/x"}},
+		{Run0, true, []string{"run0", "--no-ask-password", "rm", "-f", "/usr/local/bin/x"}},
+		{Run0, false, []string{"run0", "rm", "-f", "/usr/local/bin/x"}},
+		{Pkexec, true, []string{"pkexec", "--disable-internal-agent", "rm", "-f", "/usr/local/bin/x"}},
+		{Pkexec, false, []string{"pkexec", "rm", "-f", "/usr/local/bin/x"}},
+	}
+	for _, tt := range tests {
+		got := Wrap(tt.tool, tt.nonInteractive, "rm", "-f", "/usr/local/bin/x")
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Wrap(%s, %v) = %q, want %q", tt.tool, tt.nonInteractive, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package selfupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/download"
+	"github.com/tim/autonomix-cli/pkg/github"
+	"github.com/tim/autonomix-cli/pkg/installer"
+	"github.com/tim/autonomix-cli/pkg/privilege"
+	"github.com/tim/autonomix-cli/pkg/semver"
+	"github.com/tim/autonomix-cli/pkg/system"
+)
+
+// RepoURL is the repository autonomix-cli itself is released from.
+const RepoURL = "https://github.com/timappledotcom/autonomix-cli"
+
+const binaryName = "autonomix-cli"
+
+// Result describes a completed self-update.
+type Result struct {
+	From      string
+	To        string
+	Method    string // config.InstallMethodPackage or config.InstallMethodBinary
+	Path      string
+	Changelog string
+}
+
+// Check fetches the latest autonomix release and reports whether it is
+// newer than current.
+func Check(current string, s config.Settings) (*github.Release, bool, error) {
+	rel, err := github.Client{Token: s.GitHubToken}.GetLatestRelease(RepoURL)
+	if err != nil {
+		return nil, false, err
+	}
+	return rel, isNewer(rel.TagName, current), nil
+}
+
+// DetectInstall returns the path the running executable was started as
+// and whether it is owned by a system package or was installed as a plain
+// binary. The path is not resolved: when it is a symlink into the kept
+// versions, an update replaces the link rather than the version it points
+// at.
+func DetectInstall() (string, string, error) {
+	exe, err := system.InvokedPath()
+	if err != nil {
+		return "", "", err
+	}
+
+	paths := []string{exe}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil && resolved != exe {
+		paths = append(paths, resolved)
+	}
+	for _, path := range paths {
+		owners := [][]string{
+			{"dpkg", "-S", path},
+			{"rpm", "-qf", path},
+			{"pacman", "-Qo", path},
+		}
+		for _, args := range owners {
+			if _, err := exec.LookPath(args[0]); err != nil {
+				continue
+			}
+			if exec.Command(args[0], args[1:]...).Run() == nil {
+				return exe, config.InstallMethodPackage, nil
+			}
+		}
+	}
+	return exe, config.InstallMethodBinary, nil
+}
+
+// ErrUpToDate is returned by Update when no newer release exists. It is not
+// a failure.
+var ErrUpToDate = errors.New("already up to date")
+
+// Update replaces the running autonomix-cli with the latest release using
+// the same install method it was originally installed with.
+func Update(current string, out io.Writer, s config.Settings) (*Result, error) {
+	rel, newer, err := Check(current, s)
+	if err != nil {
+		return nil, err
+	}
+	if !newer {
+		return nil, ErrUpToDate
+	}
+
+	exe, method, err := DetectInstall()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{From: current, To: rel.TagName, Method: method, Path: exe, Changelog: rel.Body}
+
+	if method == config.InstallMethodPackage {
+		p := installer.New(installer.Request{
+			App: config.App{
+				Name:          binaryName,
+				RepoURL:       RepoURL,
+				InstallMethod: config.InstallMethodPackage,
+			},
+			Release:  rel,
+			Settings: s,
+		})
+		p.Stdout = out
+		p.OnProgress = download.LineMeter(out)
+		if _, err := p.Run(); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if err := replaceExecutable(rel, exe, out, binary.NewOptions(s).Escalator); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replaceExecutable downloads the release archive for this platform,
+// verifies it and atomically swaps it in for the executable at exe.
+func replaceExecutable(rel *github.Release, exe string, out io.Writer, esc privilege.Escalator) error {
+	selected, err := installer.SelectBinary(binary.DetectBinaryAssets(rel), "", rel.TagName)
+	if err != nil {
+		return fmt.Errorf("no release asset found for this platform")
+	}
+	asset := selected.Asset
+
+	dir, err := os.MkdirTemp("", "autonomix-self-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, asset.Name)
+	fmt.Fprintf(out, "Downloading %s...\n", asset.BrowserDownloadURL)
+	d := download.New()
+	d.OnProgress = download.LineMeter(out)
+	if err := d.Fetch(context.Background(), asset.BrowserDownloadURL, archive); err != nil {
+		return err
+	}
+
+	digest, verified, err := installer.VerifyFile(rel, &asset, archive)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return fmt.Errorf("release publishes no checksum for %s, refusing to replace the executable", asset.Name)
+	}
+	fmt.Fprintf(out, "Verified sha256 %s\n", digest)
+
+	extracted, err := binary.ExtractBinary(archive, binaryName)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(extracted)
+
+	return binary.ReplaceFile(extracted, exe, esc)
+}
+
+// isNewer reports whether release tag a is newer than version b. Versions
+// that are not semantic versions are never newer.
+func isNewer(a, b string) bool {
+	va, okA := semver.Parse(a)
+	vb, okB := semver.Parse(b)
+	return okA && okB && semver.Compare(va, vb) > 0
+}
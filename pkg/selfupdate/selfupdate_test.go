@@ -0,0 +1,25 @@
+package selfupdate
+
+import "testing"
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v0.4.0", "v0.3.0", true},
+		{"v0.3.0", "v0.3.0", false},
+		{"v0.3.0", "0.3.0", false},
+		{"v0.10.0", "v0.9.1", true},
+		{"v1.0", "v0.99.99", true},
+		{"v0.3.1", "v0.3.1-rc1", true},
+		{"v0.3.1-rc1", "v0.3.1", false},
+		{"v0.4.0", "dev", false},
+		{"v0.2.9", "v0.3.0", false},
+	}
+	for _, tt := range tests {
+		if got := isNewer(tt.a, tt.b); got != tt.want {
+			t.Errorf("isNewer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
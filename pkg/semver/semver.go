@@ -0,0 +1,118 @@
+// Package semver parses and compares the semantic versions found in
+// release tags.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is dropped.
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string // Prerelease identifiers, e.g. "rc.1"
+}
+
+// Parse reads versions such as "1.2.3", "v1.2.3-rc.1" or tags with a name
+// prefix like "jq-1.7.1". Missing minor and patch numbers count as 0.
+func Parse(s string) (Version, bool) {
+	s = strings.TrimSpace(s)
+	// Skip a tag prefix up to the first digit
+	start := strings.IndexAny(s, "0123456789")
+	if start == -1 {
+		return Version{}, false
+	}
+	if start > 0 && !strings.ContainsAny(s[start-1:start], "v-_/") {
+		return Version{}, false
+	}
+	s = s[start:]
+
+	if idx := strings.IndexByte(s, '+'); idx != -1 {
+		s = s[:idx]
+	}
+	var v Version
+	if idx := strings.IndexByte(s, '-'); idx != -1 {
+		v.Pre = s[idx+1:]
+		s = s[:idx]
+		if v.Pre == "" {
+			return Version{}, false
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, false
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, false
+		}
+		*nums[i] = n
+	}
+	return v, true
+}
+
+// String formats v without a prefix, e.g. "1.2.3-rc.1".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as a is older than, equal to or newer than b,
+// following semver precedence: a prerelease sorts before its release.
+func Compare(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	}
+
+	pa, pb := strings.Split(a.Pre, "."), strings.Split(b.Pre, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if c := compareIdentifier(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(pa) - len(pb))
+}
+
+// compareIdentifier orders numeric identifiers numerically and before
+// alphanumeric ones, which compare as strings.
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(na - nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
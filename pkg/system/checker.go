@@ -7,9 +7,24 @@ import (
 	"github.com/tim/autonomix-cli/pkg/packages"
 )
 
+// UnknownVersion is the version of a binary found in PATH that does not
+// report one, so that it still reads as installed.
+const UnknownVersion = "detected"
+
 // CheckInstalled checks if an application is installed via various package managers.
-// It returns the version string, the package type, true if found.
+// It returns the version string, the package type, true if found. The
+// version is UnknownVersion for a binary in PATH that does not report one.
 func CheckInstalled(appName string) (string, packages.Type, bool) {
+	return checkInstalled(appName, true)
+}
+
+// FindInstalled is CheckInstalled for dry runs: a binary found in PATH is
+// not run to ask its version, so its version is empty.
+func FindInstalled(appName string) (string, packages.Type, bool) {
+	return checkInstalled(appName, false)
+}
+
+func checkInstalled(appName string, probe bool) (string, packages.Type, bool) {
 	// Generate candidate names to check
 	// e.g. "My App" -> ["My App", "my app", "my-app"]
 	candidates := []string{appName}
@@ -80,7 +95,7 @@ func CheckInstalled(appName string) (string, packages.Type, bool) {
 		}
 
 		// Check Binary in Path (Fallback)
-		if ver, ok := checkBinary(name); ok {
+		if ver, ok := checkBinary(name, probe); ok {
 			return ver, packages.Unknown, true
 		}
 	}
@@ -88,73 +103,29 @@ func CheckInstalled(appName string) (string, packages.Type, bool) {
 	return "", packages.Unknown, false
 }
 
-func checkBinary(name string) (string, bool) {
+// checkBinary finds name in PATH and with probe runs it for its version.
+// Without probe the version is empty; a binary that does not report one
+// gets UnknownVersion.
+func checkBinary(name string, probe bool) (string, bool) {
 	path, err := exec.LookPath(name)
 	if err != nil {
 		return "", false
 	}
-	
-	// Try to get version
-	versionArgs := [][]string{
-		{"--version"},
-		{"-v"},
-		{"version"},
-	}
-
-	for _, args := range versionArgs {
-		cmd := exec.Command(path, args...)
-		out, err := cmd.Output()
-		if err == nil && len(out) > 0 {
-			// clean up output, take first line
-			ver := strings.TrimSpace(string(out))
-			if idx := strings.Index(ver, "\n"); idx != -1 {
-				ver = ver[:idx]
-			}
-			return ver, true
-		}
+	if !probe {
+		return "", true
 	}
-	
-	return "detected", true
+	res, err := ProbeVersion(path, VersionProbe{})
+	if err != nil || res.Version == "" {
+		return UnknownVersion, true
+	}
+	return res.Version, true
 }
 
 // GetSystemPreferredType returns the preferred package type for the running system
 func GetSystemPreferredType() packages.Type {
-	// Use /etc/os-release for accurate detection
-	osID := getOSID()
-	
-	switch osID {
-	case "arch", "manjaro", "endeavouros", "garuda":
-		return packages.Pacman
-	case "debian", "ubuntu", "linuxmint", "pop", "elementary":
-		return packages.Deb
-	case "fedora", "rhel", "centos", "rocky", "almalinux":
-		return packages.Rpm
-	}
-	
-	// Fallback to checking for package managers
-	if _, err := exec.LookPath("pacman"); err == nil {
-		return packages.Pacman
-	}
-	if _, err := exec.LookPath("dpkg"); err == nil {
-		return packages.Deb
-	}
-	if _, err := exec.LookPath("rpm"); err == nil {
-		return packages.Rpm
-	}
-	return packages.Unknown
+	return DetectPackageManager().Type()
 }
 
-func getOSID() string {
-	// Read /etc/os-release and extract ID
-	cmd := exec.Command("sh", "-c", ". /etc/os-release && echo $ID")
-	out, err := cmd.Output()
-	if err == nil {
-		return strings.TrimSpace(string(out))
-	}
-	return ""
-}
-
-
 func checkSnap(name string) (string, bool) {
 	// snap list name
 	cmd := exec.Command("snap", "list", name)
@@ -0,0 +1,21 @@
+package system
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckBinary_NoVersionOutput(t *testing.T) {
+	path := writeScript(t, "exit 0\n")
+	t.Setenv("PATH", filepath.Dir(path))
+
+	if ver, ok := checkBinary("tool", true); !ok || ver != UnknownVersion {
+		t.Errorf("checkBinary probed = (%q, %v), want (%q, true)", ver, ok, UnknownVersion)
+	}
+	if ver, ok := checkBinary("tool", false); !ok || ver != "" {
+		t.Errorf("checkBinary unprobed = (%q, %v), want (\"\", true)", ver, ok)
+	}
+	if _, ok := checkBinary("missing-tool", true); ok {
+		t.Error("checkBinary found a binary that is not in PATH")
+	}
+}
@@ -0,0 +1,34 @@
+package system
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// InvokedPath returns the path the running executable was started through,
+// which may be a symlink. os.Executable resolves links on Linux, so the
+// path is found from os.Args[0] and used when it leads to the same file.
+func InvokedPath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	path := os.Args[0]
+	if !strings.Contains(path, "/") {
+		if path, err = exec.LookPath(path); err != nil {
+			return exe, nil
+		}
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return exe, nil
+	}
+	a, errA := os.Stat(path)
+	b, errB := os.Stat(exe)
+	if errA != nil || errB != nil || !os.SameFile(a, b) {
+		return exe, nil
+	}
+	return path, nil
+}
@@ -0,0 +1,185 @@
+package system
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Source is where Inventory found an installed tool.
+type Source string
+
+const (
+	SourcePath    Source = "path" // An executable in PATH
+	SourceDpkg    Source = "dpkg"
+	SourceRpm     Source = "rpm"
+	SourcePacman  Source = "pacman"
+	SourceFlatpak Source = "flatpak"
+	SourceSnap    Source = "snap"
+	SourceBrew    Source = "brew"
+)
+
+// Installed is a tool found on the system.
+type Installed struct {
+	Name     string // Package, application ID or executable name
+	Version  string // As reported; empty for executables in PATH
+	Source   Source
+	Path     string // The executable, for SourcePath
+	Homepage string // Upstream URL from the package metadata, if any
+}
+
+// listers query each package manager; ones not installed are skipped.
+var listers = []struct {
+	tool string
+	args []string
+	list func(out []byte) []Installed
+}{
+	{"dpkg-query", []string{"-W", "-f=${Package}\t${Version}\t${Homepage}\n"}, func(out []byte) []Installed { return parseTabbed(out, SourceDpkg) }},
+	{"rpm", []string{"-qa", "--qf", "%{NAME}\t%{VERSION}\t%{URL}\n"}, func(out []byte) []Installed { return parseTabbed(out, SourceRpm) }},
+	{"pacman", []string{"-Qi"}, parsePacman},
+	{"flatpak", []string{"list", "--app", "--columns=application,version"}, func(out []byte) []Installed { return parseTabbed(out, SourceFlatpak) }},
+	{"snap", []string{"list"}, parseSnap},
+	{"brew", []string{"info", "--json=v2", "--installed"}, parseBrew},
+}
+
+// Inventory lists the packages installed through every package manager
+// present, followed by the executables in PATH.
+func Inventory() []Installed {
+	var found []Installed
+	for _, l := range listers {
+		if _, err := exec.LookPath(l.tool); err != nil {
+			continue
+		}
+		out, err := exec.Command(l.tool, l.args...).Output()
+		if err != nil {
+			continue
+		}
+		found = append(found, l.list(out)...)
+	}
+	return append(found, pathExecutables(filepath.SplitList(os.Getenv("PATH")))...)
+}
+
+// pathExecutables lists the executables in dirs. Like a shell, the first
+// directory providing a name wins.
+func pathExecutables(dirs []string) []Installed {
+	var found []Installed
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if seen[e.Name()] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+				continue
+			}
+			seen[e.Name()] = true
+			found = append(found, Installed{Name: e.Name(), Source: SourcePath, Path: path})
+		}
+	}
+	return found
+}
+
+// parseTabbed reads "name<TAB>version[<TAB>homepage]" lines.
+func parseTabbed(out []byte, source Source) []Installed {
+	var found []Installed
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		item := Installed{Name: fields[0], Version: fields[1], Source: source}
+		if len(fields) > 2 && fields[2] != "(none)" {
+			item.Homepage = fields[2]
+		}
+		found = append(found, item)
+	}
+	return found
+}
+
+// parsePacman reads the blank-line separated records of pacman -Qi.
+func parsePacman(out []byte) []Installed {
+	var found []Installed
+	var item Installed
+	flush := func() {
+		if item.Name != "" {
+			item.Source = SourcePacman
+			found = append(found, item)
+		}
+		item = Installed{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch strings.TrimSpace(key) {
+		case "Name":
+			item.Name = val
+		case "Version":
+			item.Version = val
+		case "URL":
+			if val != "None" {
+				item.Homepage = val
+			}
+		}
+	}
+	flush()
+	return found
+}
+
+// parseSnap reads the table printed by snap list.
+func parseSnap(out []byte) []Installed {
+	var found []Installed
+	lines := strings.Split(string(out), "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 2 {
+			continue
+		}
+		found = append(found, Installed{Name: fields[0], Version: fields[1], Source: SourceSnap})
+	}
+	return found
+}
+
+// parseBrew reads the formulae of brew info --json=v2 --installed.
+func parseBrew(out []byte) []Installed {
+	var info struct {
+		Formulae []struct {
+			Name      string `json:"name"`
+			Homepage  string `json:"homepage"`
+			Installed []struct {
+				Version string `json:"version"`
+			} `json:"installed"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil
+	}
+
+	var found []Installed
+	for _, f := range info.Formulae {
+		item := Installed{Name: f.Name, Homepage: f.Homepage, Source: SourceBrew}
+		if n := len(f.Installed); n > 0 {
+			item.Version = f.Installed[n-1].Version
+		}
+		found = append(found, item)
+	}
+	return found
+}
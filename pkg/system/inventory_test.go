@@ -0,0 +1,84 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTabbed(t *testing.T) {
+	out := "ripgrep\t14.1.0-1\thttps://github.com/BurntSushi/ripgrep\nlibc6\t2.36-9\t\nfd\t9.0.0\t(none)\n\n"
+	want := []Installed{
+		{Name: "ripgrep", Version: "14.1.0-1", Source: SourceDpkg, Homepage: "https://github.com/BurntSushi/ripgrep"},
+		{Name: "libc6", Version: "2.36-9", Source: SourceDpkg},
+		{Name: "fd", Version: "9.0.0", Source: SourceDpkg},
+	}
+	if got := parseTabbed([]byte(out), SourceDpkg); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTabbed = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePacman(t *testing.T) {
+	out := `Name            : ripgrep
+Version         : 14.1.1-1
+Description     : A search tool: fast
+URL             : https://github.com/BurntSushi/ripgrep
+Licenses        : MIT
+
+Name            : filesystem
+Version         : 2024.04.07-1
+URL             : None
+`
+	want := []Installed{
+		{Name: "ripgrep", Version: "14.1.1-1", Source: SourcePacman, Homepage: "https://github.com/BurntSushi/ripgrep"},
+		{Name: "filesystem", Version: "2024.04.07-1", Source: SourcePacman},
+	}
+	if got := parsePacman([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePacman = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSnap(t *testing.T) {
+	out := "Name    Version   Rev    Tracking       Publisher   Notes\ncore22  20240111  1122   latest/stable  canonical✓  base\nyq      v4.44.3   2634   latest/stable  mikefarah   -\n"
+	want := []Installed{
+		{Name: "core22", Version: "20240111", Source: SourceSnap},
+		{Name: "yq", Version: "v4.44.3", Source: SourceSnap},
+	}
+	if got := parseSnap([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSnap = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBrew(t *testing.T) {
+	out := `{"formulae": [{"name": "fzf", "homepage": "https://github.com/junegunn/fzf", "installed": [{"version": "0.55.0"}]}], "casks": []}`
+	want := []Installed{{Name: "fzf", Version: "0.55.0", Source: SourceBrew, Homepage: "https://github.com/junegunn/fzf"}}
+	if got := parseBrew([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBrew = %+v, want %+v", got, want)
+	}
+}
+
+func TestPathExecutables(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for _, f := range []struct {
+		dir, name string
+		mode      os.FileMode
+	}{
+		{first, "rg", 0755},
+		{first, "README", 0644},
+		{second, "rg", 0755},
+		{second, "fzf", 0755},
+	} {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), nil, f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []Installed{
+		{Name: "rg", Source: SourcePath, Path: filepath.Join(first, "rg")},
+		{Name: "fzf", Source: SourcePath, Path: filepath.Join(second, "fzf")},
+	}
+	if got := pathExecutables([]string{first, second}); !reflect.DeepEqual(got, want) {
+		t.Errorf("pathExecutables = %+v, want %+v", got, want)
+	}
+}
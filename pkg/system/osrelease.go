@@ -0,0 +1,119 @@
+package system
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// osReleasePaths are read in order; /usr/lib/os-release is the fallback
+// the os-release specification defines.
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// OSRelease identifies the running distribution, as described by
+// os-release(5).
+type OSRelease struct {
+	ID         string
+	IDLike     []string
+	VersionID  string
+	PrettyName string
+}
+
+// IDs returns ID followed by the IDs in ID_LIKE, most specific first.
+func (r *OSRelease) IDs() []string {
+	if r == nil {
+		return nil
+	}
+	var ids []string
+	if r.ID != "" {
+		ids = append(ids, r.ID)
+	}
+	return append(ids, r.IDLike...)
+}
+
+// ReadOSRelease reads the running system's os-release file.
+func ReadOSRelease() (*OSRelease, error) {
+	var err error
+	for _, path := range osReleasePaths {
+		var r *OSRelease
+		if r, err = readOSRelease(path); err == nil {
+			return r, nil
+		}
+	}
+	return nil, err
+}
+
+func readOSRelease(path string) (*OSRelease, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseOSRelease(f)
+}
+
+// ParseOSRelease parses os-release content. Lines that are not valid
+// assignments are ignored, as the specification asks.
+func ParseOSRelease(r io.Reader) (*OSRelease, error) {
+	vals := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if val, ok = unquote(val); ok {
+			vals[key] = val
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	rel := &OSRelease{
+		ID:         strings.ToLower(vals["ID"]),
+		IDLike:     strings.Fields(strings.ToLower(vals["ID_LIKE"])),
+		VersionID:  vals["VERSION_ID"],
+		PrettyName: vals["PRETTY_NAME"],
+	}
+	if rel.ID == "" {
+		// The specification's default
+		rel.ID = "linux"
+	}
+	if rel.PrettyName == "" {
+		rel.PrettyName = "Linux"
+	}
+	return rel, nil
+}
+
+// unquote decodes an os-release value: shell-style double quotes with
+// backslash escapes, single quotes taken literally, or a bare word.
+func unquote(val string) (string, bool) {
+	if len(val) < 2 || (val[0] != '"' && val[0] != '\'') {
+		return val, true
+	}
+	quote := val[0]
+	if val[len(val)-1] != quote {
+		return "", false
+	}
+	val = val[1 : len(val)-1]
+	if quote == '\'' {
+		return val, true
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		if c == '\\' && i+1 < len(val) && strings.IndexByte("\"\\`$", val[i+1]) != -1 {
+			i++
+			c = val[i]
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), true
+}
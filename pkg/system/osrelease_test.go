@@ -0,0 +1,68 @@
+package system
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	input := `# comment
+ID="opensuse-tumbleweed"
+ID_LIKE='opensuse suse'
+VERSION_ID=20241015
+PRETTY_NAME="openSUSE \"Tumbleweed\" \$HOME"
+not an assignment
+BROKEN="unterminated
+`
+	rel, err := ParseOSRelease(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.ID != "opensuse-tumbleweed" {
+		t.Errorf("ID = %q", rel.ID)
+	}
+	if !slices.Equal(rel.IDLike, []string{"opensuse", "suse"}) {
+		t.Errorf("IDLike = %q", rel.IDLike)
+	}
+	if rel.VersionID != "20241015" {
+		t.Errorf("VersionID = %q", rel.VersionID)
+	}
+	if want := `openSUSE "Tumbleweed" $HOME`; rel.PrettyName != want {
+		t.Errorf("PrettyName = %q, want %q", rel.PrettyName, want)
+	}
+}
+
+func TestParseOSReleaseDefaults(t *testing.T) {
+	rel, err := ParseOSRelease(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.ID != "linux" || rel.PrettyName != "Linux" {
+		t.Errorf("got ID %q, PrettyName %q", rel.ID, rel.PrettyName)
+	}
+}
+
+func TestOSReleaseFixtures(t *testing.T) {
+	tests := []struct {
+		file    string
+		ids     []string
+		version string
+		pretty  string
+	}{
+		{"ubuntu", []string{"ubuntu", "debian"}, "24.04", "Ubuntu 24.04.1 LTS"},
+		{"kali", []string{"kali", "debian"}, "2024.3", "Kali GNU/Linux Rolling"},
+		{"centos7", []string{"centos", "rhel", "fedora"}, "7", "CentOS Linux 7 (Core)"},
+		{"cachyos", []string{"cachyos", "arch"}, "", "CachyOS"},
+	}
+	for _, tt := range tests {
+		rel, err := readOSRelease(filepath.Join("testdata", "os-release", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(rel.IDs(), tt.ids) || rel.VersionID != tt.version || rel.PrettyName != tt.pretty {
+			t.Errorf("%s: got %q %q %q", tt.file, rel.IDs(), rel.VersionID, rel.PrettyName)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package system
+
+import (
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tim/autonomix-cli/pkg/packages"
+)
+
+// PackageManager is a distribution's high-level package tool. Local
+// packages are installed through it rather than dpkg or rpm so their
+// dependencies are resolved.
+type PackageManager string
+
+const (
+	Apt    PackageManager = "apt-get"
+	Dnf    PackageManager = "dnf"
+	Yum    PackageManager = "yum"
+	Zypper PackageManager = "zypper"
+	Pacman PackageManager = "pacman"
+)
+
+// distroManagers maps distribution IDs, as found in ID or ID_LIKE, to the
+// package managers they may use, preferred first.
+var distroManagers = map[string][]PackageManager{
+	"debian":     {Apt},
+	"ubuntu":     {Apt},
+	"linuxmint":  {Apt},
+	"pop":        {Apt},
+	"elementary": {Apt},
+
+	"fedora":    {Dnf, Yum},
+	"rhel":      {Dnf, Yum},
+	"centos":    {Dnf, Yum},
+	"rocky":     {Dnf, Yum},
+	"almalinux": {Dnf, Yum},
+
+	"suse":                {Zypper},
+	"opensuse":            {Zypper},
+	"opensuse-leap":       {Zypper},
+	"opensuse-tumbleweed": {Zypper},
+	"sles":                {Zypper},
+
+	"arch":        {Pacman},
+	"manjaro":     {Pacman},
+	"endeavouros": {Pacman},
+	"garuda":      {Pacman},
+}
+
+// fallbackManagers are looked for in PATH when os-release names no known
+// distribution.
+var fallbackManagers = []PackageManager{Apt, Dnf, Zypper, Yum, Pacman}
+
+// Type returns the package format pm installs.
+func (pm PackageManager) Type() packages.Type {
+	switch pm {
+	case Apt:
+		return packages.Deb
+	case Dnf, Yum, Zypper:
+		return packages.Rpm
+	case Pacman:
+		return packages.Pacman
+	}
+	return packages.Unknown
+}
+
+// InstallArgs returns the command installing the local package at path
+// without prompting.
+func (pm PackageManager) InstallArgs(path string) []string {
+	// apt and zypper only treat arguments that look like paths as files
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	switch pm {
+	case Apt:
+		return []string{"apt-get", "install", "-y", path}
+	case Dnf, Yum:
+		return []string{string(pm), "install", "-y", path}
+	case Zypper:
+		// Release assets are rarely signed with a key zypper knows
+		return []string{"zypper", "--non-interactive", "install", "--allow-unsigned-rpm", path}
+	case Pacman:
+		return []string{"pacman", "-U", "--noconfirm", path}
+	}
+	return nil
+}
+
+// DetectPackageManager returns the package manager of the running system,
+// or "" when none is found.
+func DetectPackageManager() PackageManager {
+	rel, _ := ReadOSRelease()
+	return packageManagerFor(rel.IDs(), exec.LookPath)
+}
+
+// packageManagerFor picks the package manager for a distribution identified
+// by ids, most specific first, falling back to whichever known manager
+// lookPath finds.
+func packageManagerFor(ids []string, lookPath func(string) (string, error)) PackageManager {
+	installed := func(pm PackageManager) bool {
+		_, err := lookPath(string(pm))
+		return err == nil
+	}
+
+	for _, id := range ids {
+		candidates := distroManagers[id]
+		for _, pm := range candidates {
+			if installed(pm) {
+				return pm
+			}
+		}
+		if len(candidates) > 0 {
+			// Trust os-release even when the tool is not in PATH
+			return candidates[0]
+		}
+	}
+
+	for _, pm := range fallbackManagers {
+		if installed(pm) {
+			return pm
+		}
+	}
+	return ""
+}
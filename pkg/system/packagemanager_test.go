@@ -0,0 +1,74 @@
+package system
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tim/autonomix-cli/pkg/packages"
+)
+
+// lookPathOf returns a lookPath that finds only the given tools.
+func lookPathOf(tools ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if slices.Contains(tools, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestPackageManagerFor(t *testing.T) {
+	tests := []struct {
+		file      string
+		installed []string
+		want      PackageManager
+	}{
+		{"ubuntu", []string{"apt-get", "dpkg"}, Apt},
+		{"kali", []string{"apt-get"}, Apt},
+		{"zorin", []string{"apt-get"}, Apt},
+		{"fedora", []string{"dnf", "yum", "rpm"}, Dnf},
+		{"centos7", []string{"yum", "rpm"}, Yum},
+		{"nobara", []string{"dnf"}, Dnf},
+		{"opensuse-tumbleweed", []string{"zypper", "rpm"}, Zypper},
+		{"cachyos", []string{"pacman"}, Pacman},
+		// Unknown distributions fall back to what is installed
+		{"nixos", []string{"pacman"}, Pacman},
+		{"nixos", nil, ""},
+		// os-release is trusted over PATH
+		{"fedora", nil, Dnf},
+	}
+	for _, tt := range tests {
+		rel, err := readOSRelease(filepath.Join("testdata", "os-release", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := packageManagerFor(rel.IDs(), lookPathOf(tt.installed...)); got != tt.want {
+			t.Errorf("%s with %v: got %q, want %q", tt.file, tt.installed, got, tt.want)
+		}
+	}
+}
+
+func TestInstallArgs(t *testing.T) {
+	path := "/tmp/app-1.0.0.x86_64.rpm"
+	tests := []struct {
+		pm   PackageManager
+		want []string
+		typ  packages.Type
+	}{
+		{Apt, []string{"apt-get", "install", "-y", path}, packages.Deb},
+		{Dnf, []string{"dnf", "install", "-y", path}, packages.Rpm},
+		{Yum, []string{"yum", "install", "-y", path}, packages.Rpm},
+		{Zypper, []string{"zypper", "--non-interactive", "install", "--allow-unsigned-rpm", path}, packages.Rpm},
+		{Pacman, []string{"pacman", "-U", "--noconfirm", path}, packages.Pacman},
+	}
+	for _, tt := range tests {
+		if got := tt.pm.InstallArgs(path); !slices.Equal(got, tt.want) {
+			t.Errorf("%s.InstallArgs = %q, want %q", tt.pm, got, tt.want)
+		}
+		if got := tt.pm.Type(); got != tt.typ {
+			t.Errorf("%s.Type = %s, want %s", tt.pm, got, tt.typ)
+		}
+	}
+}
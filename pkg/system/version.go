@@ -0,0 +1,155 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTimeout bounds each run of a binary asked for its version.
+const DefaultProbeTimeout = 5 * time.Second
+
+// DefaultVersionArgs are tried in order when a probe sets no arguments.
+var DefaultVersionArgs = [][]string{{"--version"}, {"-V"}, {"-v"}, {"version"}}
+
+// versionPattern finds a dotted version not preceded by a digit or dot,
+// with an optional prerelease suffix: "14.1.0" in "ripgrep 14.1.0 (rev
+// abc)", "1.22.1" in "go1.22.1", "1.7.1" in "jq-1.7.1".
+var versionPattern = regexp.MustCompile(`(?:^|[^\d.])(\d+\.\d+(?:\.\d+)*(?:-(?:rc|alpha|beta|pre|dev)[0-9A-Za-z.]*)?)`)
+
+// keywordPattern prefers a version labelled as such, as in
+// "commit=abc, version=0.44.1".
+var keywordPattern = regexp.MustCompile(`(?i)\bversion\s*[:=]?\s*v?(\d+\.\d+(?:\.\d+)*(?:-(?:rc|alpha|beta|pre|dev)[0-9A-Za-z.]*)?)`)
+
+// VersionProbe describes how to ask a binary for its version.
+type VersionProbe struct {
+	// Args are the argument lists tried in order; empty uses
+	// DefaultVersionArgs.
+	Args [][]string
+
+	// Pattern extracts the version from the output: its first capture
+	// group, or the whole match without one. Empty uses the built-in
+	// patterns.
+	Pattern string
+
+	// Timeout bounds each run; zero uses DefaultProbeTimeout.
+	Timeout time.Duration
+}
+
+// ProbeResult is what a probe found.
+type ProbeResult struct {
+	Args    []string // The arguments that produced the output
+	Output  string   // The output's first non-empty line
+	Version string   // The version extracted; empty when none was found
+}
+
+// ProbeVersion runs the binary at path with each argument list of probe
+// until its output contains a version. Every run gets no stdin and is
+// killed after the timeout, so a binary that waits for input or ignores
+// the flags cannot hang the caller. When no output contains a version the
+// first output is returned with an empty Version; an error is returned
+// only when nothing produced output.
+func ProbeVersion(path string, probe VersionProbe) (*ProbeResult, error) {
+	pattern, err := probe.compile()
+	if err != nil {
+		return nil, err
+	}
+	args := probe.Args
+	if len(args) == 0 {
+		args = DefaultVersionArgs
+	}
+	timeout := probe.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+
+	var first *ProbeResult
+	var errs []error
+	for _, a := range args {
+		out, err := runProbe(path, a, timeout)
+		if out == "" {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", path, strings.Join(a, " "), err))
+			}
+			continue
+		}
+		res := &ProbeResult{Args: a, Output: firstLine(out), Version: ExtractVersion(out, pattern)}
+		if res.Version != "" {
+			return res, nil
+		}
+		if first == nil {
+			first = res
+		}
+	}
+	if first != nil {
+		return first, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%s printed nothing", path)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (p VersionProbe) compile() (*regexp.Regexp, error) {
+	if p.Pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern: %w", err)
+	}
+	return re, nil
+}
+
+// runProbe returns the combined output of one run; some tools print their
+// version to stderr or exit non-zero after printing it.
+func runProbe(path string, args []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	// A nil Stdin reads from the null device
+	cmd.Stdin = nil
+	// Do not wait on children that keep the output open after a kill
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	return strings.TrimSpace(string(out)), err
+}
+
+// ExtractVersion finds the version in output: with pattern, its first
+// capture group or whole match; otherwise a version labelled "version",
+// then the first dotted version. It returns "" when there is none.
+func ExtractVersion(output string, pattern *regexp.Regexp) string {
+	if pattern != nil {
+		m := pattern.FindStringSubmatch(output)
+		switch {
+		case m == nil:
+			return ""
+		case len(m) > 1:
+			return m[1]
+		}
+		return m[0]
+	}
+	for _, re := range []*regexp.Regexp{keywordPattern, versionPattern} {
+		if m := re.FindStringSubmatch(output); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
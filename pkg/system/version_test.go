@@ -0,0 +1,100 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExtractVersion(t *testing.T) {
+	tests := map[string]string{
+		"ripgrep 14.1.0 (rev abc)":        "14.1.0",
+		"v0.55.0":                         "0.55.0",
+		"jq-1.7.1":                        "1.7.1",
+		"0.24.0-1":                        "0.24.0",
+		"1:2.3.4-1":                       "2.3.4",
+		"go version go1.22.1 linux/amd64": "1.22.1",
+		"commit=abc123, build date=2024-09-01, version=0.44.1": "0.44.1",
+		"git version 2.39.2": "2.39.2",
+		"tool 2.0.0-rc.1":    "2.0.0-rc.1",
+		"detected":           "",
+		"build 42":           "",
+	}
+	for in, want := range tests {
+		if got := ExtractVersion(in, nil); got != want {
+			t.Errorf("ExtractVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	custom := regexp.MustCompile(`release (\d+)`)
+	if got := ExtractVersion("mytool release 42", custom); got != "42" {
+		t.Errorf("ExtractVersion with pattern = %q, want 42", got)
+	}
+}
+
+// writeScript writes an executable shell script to a temp dir.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProbeVersion(t *testing.T) {
+	// Only -V reports a version, on stderr after a failing --version
+	path := writeScript(t, `case "$1" in
+--version) echo "unknown flag" >&2; exit 2 ;;
+-V) echo "tool 3.1.4" >&2 ;;
+esac
+`)
+	res, err := ProbeVersion(path, VersionProbe{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != "3.1.4" || !slices.Equal(res.Args, []string{"-V"}) || res.Output != "tool 3.1.4" {
+		t.Errorf("ProbeVersion = %+v", res)
+	}
+
+	// Per-app arguments and pattern
+	path = writeScript(t, `[ "$1" = "about" ] && echo "mytool release 42"`)
+	res, err = ProbeVersion(path, VersionProbe{Args: [][]string{{"about"}}, Pattern: `release (\d+)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != "42" {
+		t.Errorf("ProbeVersion with args and pattern = %+v", res)
+	}
+
+	if _, err := ProbeVersion(path, VersionProbe{Pattern: "("}); err == nil {
+		t.Error("ProbeVersion accepted an invalid pattern")
+	}
+}
+
+func TestProbeVersionNoStdin(t *testing.T) {
+	// A binary reading stdin gets EOF instead of waiting for input
+	path := writeScript(t, `read line; echo "tool 1.0.0 [$line]"`)
+	res, err := ProbeVersion(path, VersionProbe{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != "1.0.0" {
+		t.Errorf("ProbeVersion = %+v", res)
+	}
+}
+
+func TestProbeVersionTimeout(t *testing.T) {
+	path := writeScript(t, "sleep 5\n")
+	start := time.Now()
+	_, err := ProbeVersion(path, VersionProbe{Args: [][]string{{"--version"}}, Timeout: 100 * time.Millisecond})
+	if err == nil {
+		t.Fatal("ProbeVersion of a hanging binary succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("ProbeVersion took %s, want it cut off by the timeout", elapsed)
+	}
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/tim/autonomix-cli/config"
+	"github.com/tim/autonomix-cli/pkg/history"
+)
+
+type historyItem struct {
+	entry history.Entry
+}
+
+func (i historyItem) Title() string {
+	title := fmt.Sprintf("%s  %s", i.entry.Time.Local().Format("2006-01-02 15:04"), i.entry.Action)
+	if v := i.entry.Versions(); v != "" {
+		title += " " + v
+	}
+	return title
+}
+
+func (i historyItem) Description() string {
+	result := installedStyle.Render("✓ ok")
+	if i.entry.Result == history.ResultFailed {
+		result = updateStyle.Render("✗ " + i.entry.Error)
+	}
+
+	desc := result
+	if i.entry.Method != "" {
+		desc += " | " + getMethodIcon(i.entry.Method) + " " + i.entry.Method
+	}
+	if i.entry.Sudo {
+		desc += " (sudo)"
+	}
+	if i.entry.Asset != "" {
+		desc += " | " + i.entry.Asset
+	}
+	return desc
+}
+
+func (i historyItem) FilterValue() string { return string(i.entry.Action) }
+
+// historyItems returns app's history, newest first.
+func historyItems(app config.App) ([]list.Item, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries = history.For(entries, app.RepoURL)
+
+	items := make([]list.Item, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		items = append(items, historyItem{entry: entries[i]})
+	}
+	return items, nil
+}
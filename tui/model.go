@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,44 +12,21 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tim/autonomix-cli/config"
 	"github.com/tim/autonomix-cli/pkg/binary"
+	"github.com/tim/autonomix-cli/pkg/download"
 	"github.com/tim/autonomix-cli/pkg/github"
-	"github.com/tim/autonomix-cli/pkg/homebrew"
 	"github.com/tim/autonomix-cli/pkg/installer"
 	"github.com/tim/autonomix-cli/pkg/manager"
 	"github.com/tim/autonomix-cli/pkg/packages"
+	"github.com/tim/autonomix-cli/pkg/selfupdate"
 	"github.com/tim/autonomix-cli/pkg/system"
 )
 
-func normalizeVersion(v string) string {
-	v = strings.TrimSpace(v)
-	// Remove "v" prefix
-	v = strings.TrimPrefix(v, "v")
-	// Remove Debian/RPM package revision suffix (e.g., "0.1.1-1" -> "0.1.1")
-	if idx := strings.LastIndex(v, "-"); idx > 0 {
-		// Only strip if what follows the dash looks like a package revision (number)
-		suffix := v[idx+1:]
-		// Check if suffix is purely numeric (Debian revision) or contains "el" (RPM dist tag)
-		if len(suffix) > 0 && (isNumeric(suffix) || strings.Contains(suffix, "el")) {
-			v = v[:idx]
-		}
-	}
-	return v
-}
-
-func isNumeric(s string) bool {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
-		}
-	}
-	return len(s) > 0
-}
-
 var (
 	docStyle         = lipgloss.NewStyle().Margin(1, 2)
 	statusStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -64,10 +42,11 @@ const (
 	viewAdd
 	viewSelectAsset
 	viewConfirmDelete
+	viewHistory
 )
 
 // Define self repo URL matching main.go to identify it
-const SelfRepoURL = "https://github.com/timappledotcom/autonomix-cli"
+const SelfRepoURL = selfupdate.RepoURL
 
 type item struct {
 	app config.App
@@ -90,10 +69,7 @@ func (i item) Description() string {
 			}
 		}
 		
-		vInstalled := normalizeVersion(i.app.Version)
-		vLatest := normalizeVersion(i.app.Latest)
-		
-		if vLatest != "" && vLatest != vInstalled {
+		if manager.UpdateAvailable(i.app) {
 			status = fmt.Sprintf("Update Available: %s -> %s", i.app.Version, i.app.Latest)
 			style = updateStyle
 		}
@@ -115,7 +91,22 @@ type Model struct {
 	// Selection for install
 	assetList list.Model
 	selectedApp *config.App
-	deleteIndex int
+	selectedRelease *github.Release
+	deleteApp   config.App
+
+	// History of the selected app
+	historyList list.Model
+	historyApp  string
+
+	// Download progress while an install is being prepared
+	progress    progress.Model
+	downloading *download.Progress
+
+	// Running autonomix version and a newer release, if one exists
+	version    string
+	selfLatest string
+	width      int
+	height     int
 }
 
 // openBrowser opens the specified URL in the default browser of the user.
@@ -136,7 +127,7 @@ func openBrowser(url string) error {
 	return exec.Command(cmd, args...).Start()
 }
 
-func NewModel(cfg *config.Config) Model {
+func NewModel(cfg *config.Config, version string) Model {
 	items := []list.Item{}
 	for _, app := range cfg.Apps {
 		items = append(items, item{app: app})
@@ -149,6 +140,9 @@ func NewModel(cfg *config.Config) Model {
 			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add repo")),
 			key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "check updates")),
 			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+			key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "choose asset")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rollback")),
+			key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "history")),
 			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "install/open")),
 		}
 	}
@@ -157,6 +151,9 @@ func NewModel(cfg *config.Config) Model {
 			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add repo")),
 			key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "check updates")),
 			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+			key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "choose asset")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rollback")),
+			key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "history")),
 			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "install/open")),
 		}
 	}
@@ -166,27 +163,42 @@ func NewModel(cfg *config.Config) Model {
 	assetsL.Title = "Select Package to Install"
 	assetsL.SetShowHelp(false)
 
+	historyL := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	historyL.SetShowHelp(false)
+	historyL.SetFilteringEnabled(false)
+
 	ti := textinput.New()
 	ti.Placeholder = "https://github.com/owner/repo"
 	ti.Focus()
 	ti.CharLimit = 156
 	ti.Width = 20
 
-	return Model{
+	m := Model{
 		list:      l,
 		input:     ti,
 		state:     viewList,
 		config:    cfg,
 		assetList: assetsL,
+		historyList: historyL,
+		progress:  progress.New(progress.WithDefaultGradient()),
+		version:   version,
+	}
+	if problems := cfg.Problems(); len(problems) > 0 {
+		m.err = fmt.Errorf("skipped malformed config entries:\n  %w", errors.Join(problems...))
 	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	// Check for updates for all tracked apps on startup
+	// Check for updates for tracked apps not checked within the interval
 	var cmds []tea.Cmd
-	for i, app := range m.config.Apps {
-		cmds = append(cmds, checkUpdateCmd(app, i))
+	settings := m.config.Settings.WithDefaults()
+	for _, app := range m.config.Apps {
+		if manager.CheckDue(app, settings) {
+			cmds = append(cmds, checkUpdateCmd(app, settings))
+		}
 	}
+	cmds = append(cmds, checkSelfUpdateCmd(m.version, settings))
 	return tea.Batch(cmds...)
 }
 
@@ -204,7 +216,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					selectedAsset := m.assetList.Items()[index].(assetItem).asset
 					m.status = fmt.Sprintf("Downloading %s...", selectedAsset.Name)
 					m.state = viewList // go back to main view while installing
-					return m, downloadAssetCmd(&selectedAsset)
+					return m, prepareInstallCmd(installer.Request{
+						App:      *m.selectedApp,
+						Release:  m.selectedRelease,
+						Asset:    &selectedAsset,
+						Settings: m.config.Settings,
+					})
 				}
 			case "esc", "q":
 				m.state = viewList
@@ -216,6 +233,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		if m.state == viewHistory {
+			switch msg.String() {
+			case "esc", "q", "h":
+				m.state = viewList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.historyList, cmd = m.historyList.Update(msg)
+			return m, cmd
+		}
+
 		if m.state == viewAdd {
 			switch msg.Type {
 			case tea.KeyEnter:
@@ -223,7 +251,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if url != "" {
 					// Optimistically clear input
 					m.input.Reset()
-					return m, checkRepoArgCmd(url)
+					return m, checkRepoArgCmd(url, m.config.Settings)
 				}
 				m.state = viewList
 				m.input.Reset()
@@ -241,29 +269,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "d":
 				// Confirmed - perform deletion
-				app := m.config.Apps[m.deleteIndex]
-				
-				// Uninstall based on method
-				switch app.InstallMethod {
-				case config.InstallMethodHomebrew:
-					cmd := exec.Command("brew", "uninstall", app.Name)
-					if err := cmd.Run(); err != nil {
-						m.err = fmt.Errorf("brew uninstall failed: %v", err)
-					}
-				case config.InstallMethodBinary:
-					if app.BinaryPath != "" {
-						if err := os.Remove(app.BinaryPath); err != nil {
-							m.err = fmt.Errorf("failed to remove binary: %v", err)
-						}
-					}
-				}
-				
-				// Remove from config
-				m.config.Apps = append(m.config.Apps[:m.deleteIndex], m.config.Apps[m.deleteIndex+1:]...)
-				config.Save(m.config)
-				m.list.RemoveItem(m.deleteIndex)
 				m.state = viewList
-				return m, nil
+				return m, removeCmd(m.deleteApp, m.config.Settings)
 			default:
 				// Cancelled
 				m.state = viewList
@@ -287,16 +294,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
 					selectedItem := m.list.Items()[index].(item)
 					
-					// Check if update available or not installed
-					vInstalled := normalizeVersion(selectedItem.app.Version)
-					vLatest := normalizeVersion(selectedItem.app.Latest)
-					
 					// Install if not installed OR update available
-					// Note: vLatest check ensures we actually found a release on GitHub
-					if vLatest != "" && (vInstalled == "" || vLatest != vInstalled) {
+					// Note: the Latest check ensures we actually found a release on GitHub
+					app := selectedItem.app
+					if app.Latest != "" && (app.Version == "" || manager.UpdateAvailable(app)) {
 						// Trigger install/update using smart auto-detection
 						m.status = fmt.Sprintf("Installing %s...", selectedItem.app.Name)
-						return m, installAppCmd(selectedItem.app, index)
+						return m, prepareInstallCmd(installer.Request{App: selectedItem.app, Method: binary.Auto, Settings: m.config.Settings})
 					}
 					
 					// Fallback to opening browser
@@ -313,23 +317,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.input.Focus()
 				return m, textinput.Blink
 			case "d":
-				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
-					m.deleteIndex = index
+				if selectedItem, ok := m.list.SelectedItem().(item); ok {
+					m.deleteApp = selectedItem.app
 					m.state = viewConfirmDelete
 				}
 				return m, nil
+			case "i":
+				// Pick the asset to install by hand
+				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
+					selectedItem := m.list.Items()[index].(item)
+					m.status = fmt.Sprintf("Fetching assets for %s...", selectedItem.app.Name)
+					return m, fetchAssetsCmd(selectedItem.app, m.config.Settings)
+				}
+			case "h":
+				// Show what autonomix has done to the selected app
+				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
+					selectedItem := m.list.Items()[index].(item)
+					items, err := historyItems(selectedItem.app)
+					if err != nil {
+						m.err = err
+						return m, nil
+					}
+					m.historyApp = selectedItem.app.Name
+					m.historyList.Title = "History: " + selectedItem.app.Name
+					m.historyList.ResetSelected()
+					m.state = viewHistory
+					return m, m.historyList.SetItems(items)
+				}
+			case "r":
+				// Switch a binary install back to the previous kept version
+				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
+					selectedItem := m.list.Items()[index].(item)
+					if selectedItem.app.InstallMethod != config.InstallMethodBinary || selectedItem.app.BinaryPath == "" {
+						m.err = fmt.Errorf("rollback is only supported for binary installs")
+						return m, nil
+					}
+					return m, rollbackCmd(selectedItem.app, m.config.Settings)
+				}
 			case "u":
 				// Check for updates for the selected item
 				if index := m.list.Index(); index >= 0 && index < len(m.list.Items()) {
 					selectedItem := m.list.Items()[index].(item)
-					return m, checkUpdateCmd(selectedItem.app, index)
+					return m, checkUpdateCmd(selectedItem.app, m.config.Settings)
 				}
 			}
 		}
 
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.width, m.height = msg.Width, msg.Height
+		m.resizeList()
+		m.assetList.SetSize(msg.Width-h, msg.Height-v)
+		m.historyList.SetSize(msg.Width-h, msg.Height-v)
+		m.progress.Width = min(msg.Width-h-4, 60)
 
 	case assetsFetchedMsg:
 		if msg.err != nil && len(msg.assets) == 0 {
@@ -344,11 +384,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		
-		// If there's an error but we have assets, it's a warning - show it as status
+		// If there's an error but we have assets, it's a warning - show it in the picker
+		m.status = ""
 		if msg.err != nil {
-			m.status = msg.err.Error()
-		} else {
-			m.status = ""
+			cmds = append(cmds, m.assetList.NewStatusMessage(msg.err.Error()))
 		}
 		
 		items := []list.Item{}
@@ -356,18 +395,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			items = append(items, assetItem{asset: a})
 		}
 		m.assetList.SetItems(items)
+		if msg.app.AssetPattern != "" {
+			if re, err := installer.CompilePattern(msg.app.AssetPattern, msg.app.Latest); err == nil {
+				for i, a := range msg.assets {
+					if re.MatchString(a.Name) {
+						m.assetList.Select(i)
+						break
+					}
+				}
+			}
+		}
 		m.assetList.Title = fmt.Sprintf("Select Asset for %s", msg.app.Name)
 		m.state = viewSelectAsset
 		m.selectedApp = &msg.app
+		m.selectedRelease = msg.release
 		// Update the app's Latest field in config now that we fetched it
-		for idx, app := range m.config.Apps {
-			if app.RepoURL == msg.app.RepoURL {
-				m.config.Apps[idx].Latest = msg.app.Latest
-				config.Save(m.config)
-				break
-			}
-		}
-		return m, nil
+		cmds = append(cmds, m.updateApp(msg.app.RepoURL, func(app *config.App) {
+			app.Latest = msg.app.Latest
+		}))
+		return m, tea.Batch(cmds...)
 
 	case repoCheckedMsg:
 
@@ -377,17 +423,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil // potentially show error
 		}
 		
-		// Logic moved to manager, but here we need to insert into list
-		// Re-load config or just use the data returned? 
-		// The Msg now needs to return the added app, not just the release.
-		
-		m.config.Apps = append(m.config.Apps, msg.app)
-		// Config is already saved by manager in the logic below (see checkRepoArgCmd)
-		
-		m.list.InsertItem(len(m.list.Items()), item{app: msg.app})
+		// The manager already saved the app; pick up the config it wrote
 		m.state = viewList
 		m.input.Reset()
-		return m, nil
+		return m, m.reloadConfig()
 
 	case updateCheckedMsg:
 		if msg.err != nil {
@@ -395,64 +434,51 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil 
 		}
 		// update the item in the list
-		idx := msg.index
-		if idx >= 0 && idx < len(m.config.Apps) {
-			m.config.Apps[idx].Latest = msg.release.TagName
-			config.Save(m.config)
-			// Update list item
-			cmd = m.list.SetItem(idx, item{app: m.config.Apps[idx]})
-			cmds = append(cmds, cmd)
+		cmds = append(cmds, m.updateApp(msg.repoURL, func(app *config.App) {
+			app.Latest = msg.release.TagName
+			app.LastChecked = time.Now().UTC().Format(time.RFC3339)
+		}))
+
+	case selfUpdateMsg:
+		if msg.latest != "" {
+			m.selfLatest = msg.latest
+			m.resizeList()
 		}
+		return m, nil
 
-	case downloadedMsg:
-		m.status = "Installing (enter password if prompted)..."
-		// Prepare install command
-		installCmd, err := installer.GetInstallCmd(msg.path)
-		if err != nil {
-			m.err = err
-			m.status = ""
-			os.Remove(msg.path) // Cleanup
-			return m, nil
+	case downloadProgressMsg:
+		m.downloading = &msg.progress
+		return m, waitForProgress(msg.ch)
+
+	case installPreparedMsg:
+		m.downloading = nil
+		if msg.err != nil {
+			return m.finishInstall(msg.app, nil, msg.err)
 		}
-		
-		// Run interactive command
-		cmd = tea.Exec(&execCmdAdapter{installCmd}, func(err error) tea.Msg {
-			os.Remove(msg.path) // Cleanup after install
-			return installFinishedMsg{err: err}
+		m.status = "Installing (enter password if prompted)..."
+		run := &pipelineExec{pipeline: msg.pipeline}
+		cmd = tea.Exec(run, func(err error) tea.Msg {
+			run.pipeline.Cleanup()
+			return installFinishedMsg{app: msg.app, result: run.result, err: err}
 		})
 		cmds = append(cmds, cmd)
 
 	case installFinishedMsg:
+		return m.finishInstall(msg.app, msg.result, msg.err)
+
+	case rolledBackMsg:
 		if msg.err != nil {
-			m.status = ""
-			m.err = fmt.Errorf("installation failed: %s", formatInstallError(msg.err))
-		} else {
-			// Success! Re-check installed version and update config
-			m.err = nil
-			m.status = "Verifying installation..."
-			if m.selectedApp != nil {
-				return m, recheckInstalledWithDelayCmd(*m.selectedApp)
-			}
+			m.err = fmt.Errorf("rollback failed: %v", msg.err)
+			return m, nil
 		}
-	
-	case installedRecheckedMsg:
-		// Update the app's version and latest in config and list
-		m.status = ""
-		for idx, app := range m.config.Apps {
-			if app.RepoURL == msg.app.RepoURL {
-				m.config.Apps[idx].Version = msg.version
-				// Also update Latest to ensure we have the correct release tag
-				if msg.latest != "" {
-					m.config.Apps[idx].Latest = msg.latest
-				}
-				config.Save(m.config)
-				// Update list item
-				cmd = m.list.SetItem(idx, item{app: m.config.Apps[idx]})
-				cmds = append(cmds, cmd)
-				break
-			}
+		cmds = append(cmds, m.reloadConfig())
+
+	case removedMsg:
+		// The app is untracked even when uninstalling it failed
+		if msg.err != nil {
+			m.err = msg.err
 		}
-		m.selectedApp = nil
+		cmds = append(cmds, m.reloadConfig())
 	}
 
 	if m.state == viewList {
@@ -469,11 +495,15 @@ func (m Model) View() string {
 	}
 
 	if m.status != "" {
+		if m.downloading != nil {
+			return fmt.Sprintf("\n  %s\n\n  %s  %s\n", m.status,
+				m.progress.ViewAs(m.downloading.Percent()), download.FormatBytes(m.downloading.Downloaded))
+		}
 		return fmt.Sprintf("\n  %s\n", m.status)
 	}
 
 	if m.state == viewConfirmDelete {
-		app := m.config.Apps[m.deleteIndex]
+		app := m.deleteApp
 		msg := fmt.Sprintf("\n  Delete %s?\n\n", app.Name)
 		if app.InstallMethod == config.InstallMethodHomebrew {
 			msg += "  This will uninstall via Homebrew.\n\n"
@@ -490,41 +520,104 @@ func (m Model) View() string {
 		return docStyle.Render(m.assetList.View())
 	}
 
+	if m.state == viewHistory {
+		if len(m.historyList.Items()) == 0 {
+			return fmt.Sprintf("\n  No history for %s yet.\n\n  (esc to go back)\n", m.historyApp)
+		}
+		return docStyle.Render(m.historyList.View() + "\n(esc to go back)")
+	}
+
 	if m.state == viewAdd {
 		return fmt.Sprintf(
 			"Enter GitHub Repo URL:\n\n%s\n\n(esc to cancel)\n",
 			m.input.View(),
 		)
 	}
+	if m.selfLatest != "" {
+		banner := updateStyle.Render(fmt.Sprintf("autonomix-cli %s is available (current %s) — run `autonomix-cli self-update`", m.selfLatest, m.version))
+		return docStyle.Render(banner + "\n" + m.list.View())
+	}
 	return docStyle.Render(m.list.View())
 }
 
+// resizeList fits the app list to the window, leaving room for the
+// self-update banner when it is shown.
+func (m *Model) resizeList() {
+	h, v := docStyle.GetFrameSize()
+	height := m.height - v
+	if m.selfLatest != "" {
+		height--
+	}
+	m.list.SetSize(m.width-h, height)
+}
+
+// updateConfig applies fn to the config on disk under its lock and
+// refreshes the list from the result, so edits made by other autonomix
+// processes since startup are kept.
+func (m *Model) updateConfig(fn func(*config.Config)) tea.Cmd {
+	cfg, err := config.Update(func(c *config.Config) error {
+		fn(c)
+		return nil
+	})
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	return m.setConfig(cfg)
+}
+
+// reloadConfig picks up changes written to the config by other code paths.
+func (m *Model) reloadConfig() tea.Cmd {
+	cfg, err := config.Load()
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	return m.setConfig(cfg)
+}
+
+func (m *Model) setConfig(cfg *config.Config) tea.Cmd {
+	m.config = cfg
+	items := make([]list.Item, 0, len(cfg.Apps))
+	for _, app := range cfg.Apps {
+		items = append(items, item{app: app})
+	}
+	return m.list.SetItems(items)
+}
+
+// updateApp applies fn to the app tracked under repoURL, if it still is.
+func (m *Model) updateApp(repoURL string, fn func(*config.App)) tea.Cmd {
+	return m.updateConfig(func(cfg *config.Config) {
+		if app := cfg.FindApp(repoURL); app != nil {
+			fn(app)
+		}
+	})
+}
+
 // Commands and Messages
 
+type selfUpdateMsg struct {
+	latest string
+}
+
+func checkSelfUpdateCmd(version string, s config.Settings) tea.Cmd {
+	return func() tea.Msg {
+		rel, newer, err := selfupdate.Check(version, s)
+		if err != nil || !newer {
+			return selfUpdateMsg{}
+		}
+		return selfUpdateMsg{latest: rel.TagName}
+	}
+}
+
 type repoCheckedMsg struct {
 	app config.App
 	err error
 }
 
-func checkRepoArgCmd(url string) tea.Cmd {
+func checkRepoArgCmd(url string, s config.Settings) tea.Cmd {
 	return func() tea.Msg {
-		// We need to load config first to pass to manager, 
-		// but Model has it. Accessing Model from Cmd is hard.
-		// However, we can just load it again or pass it...
-		// Simplified: We actually just want the logic to run.
-		// BUT: Manager saves the config. The Model has an in-memory copy.
-		// We should probably NOT save in manager if called from TUI? 
-		// Or we reload config in TUI?
-		
-		// Let's modify the flow.
-		// The TUI should call the manager.
-		
-		cfg, err := config.Load() 
-		if err != nil {
-			return repoCheckedMsg{err: err}
-		}
-		
-		res, err := manager.AddApp(cfg, url)
+		res, err := manager.AddApp(url, s)
 		if err != nil {
 			return repoCheckedMsg{err: err}
 		}
@@ -540,49 +633,28 @@ type assetsFetchedMsg struct {
 	err     error
 }
 
-func installAppCmd(app config.App, index int) tea.Cmd {
+func fetchAssetsCmd(app config.App, s config.Settings) tea.Cmd {
 	return func() tea.Msg {
-		rel, err := github.GetLatestRelease(app.RepoURL)
+		rel, err := manager.LatestRelease(app, s)
 		if err != nil {
-			return installFinishedMsg{err: err}
-		}
-
-		// Try package install first
-		_, err = installer.InstallUpdate(rel, &installer.InstallOptions{Method: binary.Auto})
-		if err == nil {
-			return installFinishedMsg{err: nil}
-		}
-
-		// On macOS, try Homebrew
-		if runtime.GOOS == "darwin" {
-			if homebrew.IsInstalled() {
-				if formula, err := homebrew.SearchFormula(app.Name); err == nil {
-					if err := homebrew.InstallOfficial(formula); err == nil {
-						return installFinishedMsg{err: nil}
-					}
-				}
-			}
+			return assetsFetchedMsg{err: err}
 		}
 
-		// Return the original binary install error
-		return installFinishedMsg{err: err}
-	}
-}
+		// Update app with latest release tag
+		app.Latest = rel.TagName
 
-func fetchAssetsCmd(app config.App) tea.Cmd {
-	return func() tea.Msg {
-		rel, err := github.GetLatestRelease(app.RepoURL)
-		if err != nil {
-			return assetsFetchedMsg{err: err}
+		// Standalone binaries are offered alongside packages
+		var binaries []github.Asset
+		for _, b := range binary.DetectBinaryAssets(rel) {
+			binaries = append(binaries, b.Asset)
 		}
 		
 		assets, err := installer.GetCompatibleAssets(rel)
 		if err != nil {
 			// Try to get all assets as a fallback
-			allAssets := installer.GetAllAssets(rel)
+			allAssets := append(installer.GetAllAssets(rel), binaries...)
 			if len(allAssets) > 0 {
 				// Return all assets with a warning in the error
-				app.Latest = rel.TagName
 				return assetsFetchedMsg{
 					assets: allAssets, 
 					app: app, 
@@ -593,10 +665,7 @@ func fetchAssetsCmd(app config.App) tea.Cmd {
 			return assetsFetchedMsg{err: err}
 		}
 		
-		// Update app with latest release tag
-		app.Latest = rel.TagName
-		
-		return assetsFetchedMsg{assets: assets, app: app, release: rel, err: nil}
+		return assetsFetchedMsg{assets: append(assets, binaries...), app: app, release: rel, err: nil}
 	}
 }
 
@@ -611,6 +680,9 @@ func (i assetItem) Description() string {
 	
 	sizeStr := fmt.Sprintf("Size: %d bytes", i.asset.Size)
 	typeStr := fmt.Sprintf("Type: %s", packages.DisplayName(pkgType))
+	if pkgType == packages.Unknown {
+		return fmt.Sprintf("%s | Type: Binary", sizeStr)
+	}
 	
 	// Warn if package type doesn't match system
 	warning := ""
@@ -624,90 +696,172 @@ func (i assetItem) FilterValue() string { return i.asset.Name }
 
 
 type updateCheckedMsg struct {
-	index   int
+	repoURL string
 	release *github.Release
 	err     error
 }
 
-func checkUpdateCmd(app config.App, index int) tea.Cmd {
+func checkUpdateCmd(app config.App, s config.Settings) tea.Cmd {
 	return func() tea.Msg {
-		rel, err := github.GetLatestRelease(app.RepoURL)
-		return updateCheckedMsg{index: index, release: rel, err: err}
+		rel, err := manager.LatestRelease(app, s)
+		return updateCheckedMsg{repoURL: app.RepoURL, release: rel, err: err}
 	}
 }
 
-type downloadedMsg struct {
-	path string
+type installPreparedMsg struct {
+	app      config.App
+	pipeline *installer.Pipeline
+	err      error
 }
 
 type installFinishedMsg struct {
-	err error
+	app    config.App
+	result *installer.Result
+	err    error
 }
 
-type installedRecheckedMsg struct {
-	app     config.App
-	version string
-	latest  string
+type downloadProgressMsg struct {
+	progress download.Progress
+	ch       <-chan download.Progress
 }
 
-func recheckInstalledCmd(app config.App) tea.Cmd {
-	return func() tea.Msg {
-		version, _, installed := system.CheckInstalled(app.Name)
-		if !installed {
-			// Try checking with repo name as well
-			parts := strings.Split(app.RepoURL, "/")
-			if len(parts) > 0 {
-				repoName := parts[len(parts)-1]
-				if repoName != app.Name {
-					if ver, _, ok := system.CheckInstalled(repoName); ok {
-						version = ver
-					}
-				}
+// prepareInstallCmd runs the download stages in the background, streaming
+// progress back to the model. The install stage runs afterwards via tea.Exec
+// so sudo can prompt on the terminal.
+func prepareInstallCmd(req installer.Request) tea.Cmd {
+	ch := make(chan download.Progress, 1)
+	prepare := func() tea.Msg {
+		defer close(ch)
+		p := installer.New(req)
+		p.Stdout = io.Discard
+		p.Stderr = io.Discard
+		p.OnProgress = func(pr download.Progress) {
+			// Drop updates the UI has not caught up with
+			select {
+			case ch <- pr:
+			default:
 			}
 		}
-		return installedRecheckedMsg{app: app, version: version, latest: app.Latest}
+		if err := p.Prepare(); err != nil {
+			p.Cleanup()
+			return installPreparedMsg{app: req.App, err: err}
+		}
+		return installPreparedMsg{app: req.App, pipeline: p}
 	}
+	return tea.Batch(prepare, waitForProgress(ch))
 }
 
-func recheckInstalledWithDelayCmd(app config.App) tea.Cmd {
+// waitForProgress delivers the next progress update, if any.
+func waitForProgress(ch <-chan download.Progress) tea.Cmd {
 	return func() tea.Msg {
-		// Wait for package manager database to update
-		time.Sleep(1 * time.Second)
-		version, _, installed := system.CheckInstalled(app.Name)
-		if !installed {
-			// Try checking with repo name as well
-			parts := strings.Split(app.RepoURL, "/")
-			if len(parts) > 0 {
-				repoName := parts[len(parts)-1]
-				if repoName != app.Name {
-					if ver, _, ok := system.CheckInstalled(repoName); ok {
-						version = ver
-					}
-				}
-			}
+		pr, ok := <-ch
+		if !ok {
+			return nil
 		}
-		return installedRecheckedMsg{app: app, version: version, latest: app.Latest}
+		return downloadProgressMsg{progress: pr, ch: ch}
+	}
+}
+
+// pipelineExec runs the install stage of a prepared pipeline in the
+// foreground, satisfying the tea.ExecCommand interface
+type pipelineExec struct {
+	pipeline *installer.Pipeline
+	result   *installer.Result
+}
+
+func (e *pipelineExec) Run() error {
+	res, err := e.pipeline.Install()
+	e.result = res
+	return err
+}
+
+func (e *pipelineExec) SetStdin(r io.Reader)  { e.pipeline.Stdin = r }
+func (e *pipelineExec) SetStdout(w io.Writer) { e.pipeline.Stdout = w }
+func (e *pipelineExec) SetStderr(w io.Writer) { e.pipeline.Stderr = w }
+
+// finishInstall records the install outcome for app in the config and list.
+func (m Model) finishInstall(app config.App, res *installer.Result, err error) (tea.Model, tea.Cmd) {
+	m.status = ""
+	m.downloading = nil
+	m.selectedApp = nil
+	m.selectedRelease = nil
+	if err != nil {
+		m.err = fmt.Errorf("installation failed: %s", formatInstallError(err))
+	}
+
+	if _, recordErr := manager.RecordInstall(app.RepoURL, res, err); recordErr != nil {
+		m.err = recordErr
+		return m, nil
 	}
+	return m, m.reloadConfig()
+}
+
+type rolledBackMsg struct {
+	app     config.App
+	version string
+	err     error
 }
 
-func downloadAssetCmd(asset *github.Asset) tea.Cmd {
+// rollbackExec runs a rollback in the foreground so sudo can prompt
+type rollbackExec struct {
+	app      config.App
+	settings config.Settings
+	version  string
+}
+
+func (e *rollbackExec) Run() error {
+	v, err := manager.RollbackApp(e.app, "", e.settings)
+	e.version = v
+	return err
+}
+
+func (e *rollbackExec) SetStdin(io.Reader)  {}
+func (e *rollbackExec) SetStdout(io.Writer) {}
+func (e *rollbackExec) SetStderr(io.Writer) {}
+
+func rollbackCmd(app config.App, s config.Settings) tea.Cmd {
+	run := &rollbackExec{app: app, settings: s}
+	if binary.RequiresSudo(app.BinaryPath) {
+		return tea.Exec(run, func(err error) tea.Msg {
+			return rolledBackMsg{app: app, version: run.version, err: err}
+		})
+	}
 	return func() tea.Msg {
-		path, err := installer.DownloadAsset(asset)
-		if err != nil {
-			return installFinishedMsg{err: err}
-		}
-		return downloadedMsg{path: path}
+		err := run.Run()
+		return rolledBackMsg{app: app, version: run.version, err: err}
 	}
 }
 
-// execCmdAdapter adapts exec.Cmd to satisfy tea.ExecCommand interface
-type execCmdAdapter struct {
-*exec.Cmd
+type removedMsg struct {
+	app config.App
+	err error
 }
 
-func (c *execCmdAdapter) SetStdin(r io.Reader)  { c.Stdin = r }
-func (c *execCmdAdapter) SetStdout(w io.Writer) { c.Stdout = w }
-func (c *execCmdAdapter) SetStderr(w io.Writer) { c.Stderr = w }
+// removeExec removes an app in the foreground so sudo can prompt
+type removeExec struct {
+	app      config.App
+	settings config.Settings
+}
+
+func (e *removeExec) Run() error {
+	return manager.RemoveApp(e.app, e.settings)
+}
+
+func (e *removeExec) SetStdin(io.Reader)  {}
+func (e *removeExec) SetStdout(io.Writer) {}
+func (e *removeExec) SetStderr(io.Writer) {}
+
+func removeCmd(app config.App, s config.Settings) tea.Cmd {
+	run := &removeExec{app: app, settings: s}
+	if binary.RequiresSudo(app.BinaryPath) {
+		return tea.Exec(run, func(err error) tea.Msg {
+			return removedMsg{app: app, err: err}
+		})
+	}
+	return func() tea.Msg {
+		return removedMsg{app: app, err: run.Run()}
+	}
+}
 
 // Helper functions
 